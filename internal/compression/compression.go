@@ -0,0 +1,78 @@
+package compression
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Extension returns the extension added to the name of files compressed with the given compression, i.e. ".gz".
+func Extension(compression constants.Compression) string {
+	switch compression {
+	case constants.CompressionGzip:
+		return ".gz"
+	case constants.CompressionZstd:
+		return ".zst"
+	}
+
+	return ""
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// NewWriter returns a writer that compresses the data written to it on the fly, closing it flushes the compressor but
+// doesn't close the underlying writer.
+func NewWriter(writer io.Writer, compression constants.Compression) (io.WriteCloser, error) {
+	switch compression {
+	case constants.CompressionNone:
+		return nopWriteCloser{writer}, nil
+	case constants.CompressionGzip:
+		return gzip.NewWriter(writer), nil
+	case constants.CompressionZstd:
+		return zstd.NewWriter(writer)
+	}
+
+	return nil, fmt.Errorf("unsupported compression %q", compression)
+}
+
+// fileWriter compresses the data written to a file, closing it flushes the compressor before closing the file.
+type fileWriter struct {
+	io.WriteCloser
+	file *os.File
+}
+
+func (writer *fileWriter) Close() error {
+	return errors.Join(writer.WriteCloser.Close(), writer.file.Close())
+}
+
+// CreateFile creates the file and returns a writer that compresses on the fly the data written to it.
+// The file is written as is when there is no compression.
+func CreateFile(filePath string, compression constants.Compression) (io.WriteCloser, error) {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if compression == constants.CompressionNone {
+		return file, nil
+	}
+
+	writer, err := NewWriter(file, compression)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &fileWriter{WriteCloser: writer, file: file}, nil
+}
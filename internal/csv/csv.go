@@ -2,19 +2,34 @@ package csv
 
 import (
 	"encoding/csv"
-	"log"
-	"os"
+	"fmt"
+	"io"
+
+	"github.com/akiver/cs-demo-analyzer/internal/compression"
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
 )
 
-func WriteLinesIntoCsvFile(csvFilePath string, lines [][]string) {
-	file, err := os.Create(csvFilePath)
+// WriteLinesIntoCsvFile writes the lines into the file, the compression extension is added to the file path when the
+// file is compressed.
+func WriteLinesIntoCsvFile(csvFilePath string, lines [][]string, fileCompression constants.Compression) error {
+	csvFilePath += compression.Extension(fileCompression)
+	file, err := compression.CreateFile(csvFilePath, fileCompression)
 	if err != nil {
-		log.Fatal("Error creating csv file", csvFilePath, err)
+		return fmt.Errorf("cannot create CSV file %s: %w", csvFilePath, err)
 	}
 	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	if err := writer.WriteAll(lines); err != nil {
-		log.Fatal("Cannot write CSV file", csvFilePath, err)
+	if err = WriteLines(file, lines); err != nil {
+		return fmt.Errorf("cannot write CSV file %s: %w", csvFilePath, err)
+	}
+
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("cannot write CSV file %s: %w", csvFilePath, err)
 	}
+
+	return nil
+}
+
+func WriteLines(writer io.Writer, lines [][]string) error {
+	return csv.NewWriter(writer).WriteAll(lines)
 }
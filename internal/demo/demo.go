@@ -1,7 +1,6 @@
 package demo
 
 import (
-	"errors"
 	"fmt"
 	"hash/crc64"
 	"io"
@@ -26,6 +25,8 @@ type Demo struct {
 	Type     constants.DemoType
 	// ! Checksums are not generated reading the whole .dem file but only information for the "header" because it would be slow.
 	Checksum                      string
+	checksumData                  string // Header values used to compute the checksum, the demo size is appended to it
+	Size                          int64  // Size in bytes, known only once a compressed demo has been entirely read
 	Filestamp                     string
 	Date                          time.Time
 	ServerName                    string
@@ -39,6 +40,8 @@ type Demo struct {
 	FrameRate                     float64       // Not available for Source 2 demos, it's updated during parsing
 	Duration                      time.Duration // Not available for Source 2 demos, it's updated during parsing
 	ShareCode                     string        // Valve demos only, the .info file must be next to the .dem file to be able to generate it
+	MatchInfo                     *MatchInfo    // Valve demos only, nil if the .info file is not next to the .dem file
+	Warnings                      []string      // Problems that didn't prevent reading the demo, i.e. an invalid .info file
 }
 
 var faceItDemoNameRegex = regexp.MustCompile(`/[0-9]+_team[a-z0-9-]+-Team[a-z0-9-]+_de_[a-z0-9]+\.dem/`)
@@ -53,26 +56,94 @@ var matchZyDemoNameRegex = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}_\d{2}-\d{2}-\
 // Example: "[37980] de_mirage: Trelleborg Marine Systems DK vs. ForeFeit B"
 var esportligaenServerNameRegex = regexp.MustCompile(`^\[\d+\] [a-z0-9_]+: .+ vs\. .+$`)
 
+// MatchInfo contains the values of the .info file associated with Valve demos.
+type MatchInfo struct {
+	MatchID          uint64
+	ReservationID    uint64 // Reservation ID of the last round, 0 if the file doesn't contain rounds stats
+	Date             time.Time
+	MapName          string
+	ServerIP         string
+	TvPort           uint32
+	TeamScores       []int         // Scores of the last round stats
+	Duration         time.Duration // Match duration of the last round stats
+	hasRoundStats    bool
+	decryptionKeyPub uint64 // Exposed as Demo.NetMessageDecryptionPublicKey
+}
+
+// watchableMatchInfo and roundStats contain the getters shared by the CSGO and CS2 protobuf messages.
+type watchableMatchInfo interface {
+	GetServerIp() uint32
+	GetTvPort() uint32
+	GetGameMap() string
+	GetClDecryptdataKeyPub() uint64
+}
+
+type roundStats interface {
+	GetReservationid() uint64
+	GetTeamScores() []int32
+	GetMatchDuration() int32
+}
+
+// newMatchInfo builds the match info from the protobuf message values, lastRound is nil if the message doesn't contain
+// rounds stats.
+func newMatchInfo(matchID uint64, matchTime uint32, watchable watchableMatchInfo, lastRound roundStats) *MatchInfo {
+	serverIP := watchable.GetServerIp()
+	matchInfo := &MatchInfo{
+		MatchID:          matchID,
+		Date:             getDateFromMatchTime(matchTime),
+		MapName:          watchable.GetGameMap(),
+		TvPort:           watchable.GetTvPort(),
+		decryptionKeyPub: watchable.GetClDecryptdataKeyPub(),
+	}
+	if serverIP != 0 {
+		matchInfo.ServerIP = fmt.Sprintf("%d.%d.%d.%d", byte(serverIP>>24), byte(serverIP>>16), byte(serverIP>>8), byte(serverIP))
+	}
+
+	if lastRound != nil {
+		matchInfo.hasRoundStats = true
+		matchInfo.ReservationID = lastRound.GetReservationid()
+		matchInfo.Duration = time.Duration(lastRound.GetMatchDuration()) * time.Second
+		for _, score := range lastRound.GetTeamScores() {
+			matchInfo.TeamScores = append(matchInfo.TeamScores, int(score))
+		}
+	}
+
+	return matchInfo
+}
+
+// shareCode returns the match share code, it requires the rounds stats.
+func (matchInfo *MatchInfo) shareCode() string {
+	if !matchInfo.hasRoundStats {
+		return ""
+	}
+
+	return encodeMatchShareCode(MatchInformation{
+		MatchId:       matchInfo.MatchID,
+		ReservationId: matchInfo.ReservationID,
+		TvPort:        matchInfo.TvPort,
+	})
+}
+
 // Reads the .info file associated with a demo if it exists and returns its content as bytes.
-func getMatchInfoProtoBytes(demoFilePath string) []byte {
+// An error is returned if the file exists but can't be read.
+func getMatchInfoProtoBytes(demoFilePath string) ([]byte, error) {
 	infoFilePath := demoFilePath + ".info"
 	if _, err := os.Stat(infoFilePath); err != nil {
-		return nil
+		return nil, nil
 	}
 
 	file, err := os.Open(infoFilePath)
 	if err != nil {
-		fmt.Printf("Unable to open .info file: %v", err)
-		return nil
+		return nil, fmt.Errorf("unable to open .info file: %v", err)
 	}
+	defer file.Close()
 
 	bytes, err := io.ReadAll(file)
 	if err != nil {
-		fmt.Printf("Unable to read .info file: %v", err)
-		return nil
+		return nil, fmt.Errorf("unable to read .info file: %v", err)
 	}
 
-	return bytes
+	return bytes, nil
 }
 
 func getNetMessageDecryptionKeyFromPubKey(clDecryptDataKeyPub uint64) []byte {
@@ -83,26 +154,76 @@ func getDateFromMatchTime(matchTime uint32) time.Time {
 	return time.Unix(int64(matchTime), 0)
 }
 
+// GetDemoFromPath reads the header of the demo located at the given path, the file may be compressed, see ReadDemos.
+// It returns an error if the file is an archive that contains several demos, use GetDemosFromPath in this case.
 func GetDemoFromPath(demoPath string) (*Demo, error) {
-	file, err := os.Open(demoPath)
+	demos, err := GetDemosFromPath(demoPath)
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return nil, fmt.Errorf("demo file %q not found", demoPath)
+		return nil, err
+	}
+
+	if len(demos) > 1 {
+		return nil, fmt.Errorf("the archive %q contains %d demos", demoPath, len(demos))
+	}
+
+	return demos[0], nil
+}
+
+// GetDemosFromPath reads the header of all demos contained in the file located at the given path, see ReadDemos.
+func GetDemosFromPath(demoPath string) ([]*Demo, error) {
+	var demos []*Demo
+	err := ReadDemos(demoPath, func(stream *Stream) error {
+		// Compressed demos must be read entirely to compute their checksum.
+		if err := stream.ReadToEnd(); err != nil {
+			return err
 		}
+		demos = append(demos, stream.Demo)
+
+		return nil
+	})
+
+	return demos, err
+}
+
+// GetDemoFromReader reads the demo header from the reader, name is used as the demo file path and must end with the
+// demo file name (i.e. "path/to/demo.dem").
+// matchInfoBytes is the content of the .info file associated with the demo, it's optional.
+// The reader is rewound to its beginning once the header has been read so it can be passed to the parser.
+func GetDemoFromReader(reader io.ReadSeeker, name string, matchInfoBytes []byte) (*Demo, error) {
+	size, err := reader.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = reader.Seek(0, io.SeekStart); err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	stats, err := file.Stat()
+	// There is no file modification date to fallback to, the date is available only when the match info is provided.
+	demo, err := readDemo(reader, time.Time{}, matchInfoBytes)
 	if err != nil {
 		return nil, err
 	}
+	demo.computeChecksum(size)
 
-	br := bitread.NewLargeBitReader(file)
+	if _, err = reader.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	demo.FilePath = name
+	demo.FileName = filepath.GetFileNameWithoutExtension(name)
+
+	return demo, nil
+}
+
+// readDemo reads the header of the demo, modTime is the date used when the match info is not available.
+// The checksum is not computed because it requires the demo size, see computeChecksum.
+func readDemo(reader io.Reader, modTime time.Time, matchInfoBytes []byte) (*Demo, error) {
+	br := bitread.NewLargeBitReader(reader)
 	filestamp := br.ReadCString(8)
 	isSource2 := filestamp == "PBDEMS2"
 
-	var checksum string
+	var checksumData string
 	var mapName string
 	var serverName string
 	var clientName string
@@ -113,11 +234,12 @@ func GetDemoFromPath(demoPath string) (*Demo, error) {
 	var tickRate float64
 	var networkProtocol int
 	var buildNumber int
-	var date = stats.ModTime()
+	var date = modTime
 	var shareCode string
 	var netMessageDecryptionPublicKey []byte
+	var matchInfo *MatchInfo
+	var warnings []string
 	demoType := constants.DemoTypeGOTV
-	matchInfoBytes := getMatchInfoProtoBytes(demoPath)
 
 	if isSource2 {
 		br.ReadBytes(8)
@@ -139,8 +261,8 @@ func GetDemoFromPath(demoPath string) (*Demo, error) {
 		mapName = msg.GetMapName()
 		serverName = msg.GetServerName()
 		clientName = msg.GetClientName()
-		data := fmt.Sprintf(
-			"%s%s%s%d%d%s%s%d",
+		checksumData = fmt.Sprintf(
+			"%s%s%s%d%d%s%s",
 			mapName,
 			str.RemoveInvalidUTF8Sequences(serverName),
 			str.RemoveInvalidUTF8Sequences(clientName),
@@ -148,9 +270,7 @@ func GetDemoFromPath(demoPath string) (*Demo, error) {
 			msg.GetBuildNum(),
 			msg.GetDemoVersionGuid(),
 			msg.GetDemoVersionName(),
-			stats.Size(),
 		)
-		checksum = strconv.FormatUint(crc64.Checksum([]byte(data), crc64.MakeTable(crc64.ECMA)), 16)
 
 		serverName = str.ReplaceUTF8ByteSequences(serverName)
 		clientName = str.ReplaceUTF8ByteSequences(clientName)
@@ -165,19 +285,14 @@ func GetDemoFromPath(demoPath string) (*Demo, error) {
 			m := new(msgs2.CDataGCCStrike15V2_MatchInfo)
 			err = proto.Unmarshal(matchInfoBytes, m)
 			if err != nil {
-				fmt.Printf("failed to unmarshal MatchInfo message: %v", err)
+				warnings = append(warnings, fmt.Sprintf("failed to unmarshal MatchInfo message: %v", err))
 			} else {
-				netMessageDecryptionPublicKey = getNetMessageDecryptionKeyFromPubKey(m.Watchablematchinfo.GetClDecryptdataKeyPub())
-				date = getDateFromMatchTime(m.GetMatchtime())
+				var lastRound roundStats
 				rounds := m.GetRoundstatsall()
 				if len(rounds) > 0 {
-					lastRound := rounds[len(rounds)-1]
-					shareCode = encodeMatchShareCode(MatchInformation{
-						MatchId:       m.GetMatchid(),
-						ReservationId: lastRound.GetReservationid(),
-						TvPort:        m.GetWatchablematchinfo().GetTvPort(),
-					})
+					lastRound = rounds[len(rounds)-1]
 				}
+				matchInfo = newMatchInfo(m.GetMatchid(), m.GetMatchtime(), m.GetWatchablematchinfo(), lastRound)
 			}
 		}
 	} else {
@@ -197,8 +312,8 @@ func GetDemoFromPath(demoPath string) (*Demo, error) {
 			tickRate = float64(tickCount) / duration.Seconds()
 		}
 
-		data := fmt.Sprintf(
-			"%s%s%s%d%d%d%d%d",
+		checksumData = fmt.Sprintf(
+			"%s%s%s%d%d%d%d",
 			mapName,
 			serverName,
 			clientName,
@@ -206,40 +321,37 @@ func GetDemoFromPath(demoPath string) (*Demo, error) {
 			tickCount,
 			networkProtocol,
 			signonLength,
-			stats.Size(),
 		)
-		checksum = strconv.FormatUint(crc64.Checksum([]byte(data), crc64.MakeTable(crc64.ECMA)), 16)
 
 		if len(matchInfoBytes) > 0 {
 			m := new(msg.CDataGCCStrike15V2_MatchInfo)
-			err = proto.Unmarshal(matchInfoBytes, m)
+			err := proto.Unmarshal(matchInfoBytes, m)
 			if err != nil {
-				fmt.Printf("failed to unmarshal MatchInfo message: %v", err)
+				warnings = append(warnings, fmt.Sprintf("failed to unmarshal MatchInfo message: %v", err))
 			} else {
-				netMessageDecryptionPublicKey = getNetMessageDecryptionKeyFromPubKey(m.Watchablematchinfo.GetClDecryptdataKeyPub())
-				date = getDateFromMatchTime(m.GetMatchtime())
-				lastRound := m.GetRoundstatsLegacy()
+				var lastRound roundStats
 				rounds := m.GetRoundstatsall()
-				if lastRound == nil && len(rounds) > 0 {
+				if m.GetRoundstatsLegacy() != nil {
+					lastRound = m.GetRoundstatsLegacy()
+				} else if len(rounds) > 0 {
 					lastRound = rounds[len(rounds)-1]
 				}
-				if lastRound != nil {
-					shareCode = encodeMatchShareCode(MatchInformation{
-						MatchId:       m.GetMatchid(),
-						ReservationId: lastRound.GetReservationid(),
-						TvPort:        m.GetWatchablematchinfo().GetTvPort(),
-					})
-				}
+				matchInfo = newMatchInfo(m.GetMatchid(), m.GetMatchtime(), m.GetWatchablematchinfo(), lastRound)
 			}
 		}
 	}
 
+	if matchInfo != nil {
+		netMessageDecryptionPublicKey = getNetMessageDecryptionKeyFromPubKey(matchInfo.decryptionKeyPub)
+		date = matchInfo.Date
+		shareCode = matchInfo.shareCode()
+	}
+
 	return &Demo{
-		FilePath:                      filepath.GetAbsoluteFilePath(demoPath),
-		FileName:                      filepath.GetFileNameWithoutExtension(demoPath),
+		Warnings:                      warnings,
 		Type:                          demoType,
 		Filestamp:                     filestamp,
-		Checksum:                      checksum,
+		checksumData:                  checksumData,
 		Date:                          date,
 		ServerName:                    serverName,
 		ClientName:                    clientName,
@@ -252,9 +364,17 @@ func GetDemoFromPath(demoPath string) (*Demo, error) {
 		BuildNumber:                   buildNumber,
 		NetMessageDecryptionPublicKey: netMessageDecryptionPublicKey,
 		ShareCode:                     shareCode,
+		MatchInfo:                     matchInfo,
 	}, nil
 }
 
+// computeChecksum computes the demo checksum from its header values and its size in bytes.
+func (demo *Demo) computeChecksum(fileSize int64) {
+	demo.Size = fileSize
+	data := demo.checksumData + strconv.FormatInt(fileSize, 10)
+	demo.Checksum = strconv.FormatUint(crc64.Checksum([]byte(data), crc64.MakeTable(crc64.ECMA)), 16)
+}
+
 func (demo *Demo) IsSource2() bool {
 	return demo.Filestamp == "PBDEMS2"
 }
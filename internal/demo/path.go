@@ -0,0 +1,43 @@
+package demo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrPathDisabled is returned when no demos folder is configured, demos can't be analyzed from a path.
+	ErrPathDisabled = errors.New("analyzing demos from a path is disabled")
+	// ErrPathOutsideFolder is returned when the path goes outside of the demos folder, i.e. "../myDemo.dem".
+	ErrPathOutsideFolder = errors.New("the path is outside of the demos folder")
+	// ErrNotFound is returned when the demo doesn't exist or is a folder.
+	ErrNotFound = errors.New("demo file not found")
+)
+
+// ResolvePath returns the absolute path of a demo located in the demos folder, demoPath is relative to the folder and
+// uses slashes as separator. The error is one of ErrPathDisabled, ErrPathOutsideFolder or ErrNotFound when the path
+// is rejected.
+func ResolvePath(demosFolderPath string, demoPath string) (string, error) {
+	if demosFolderPath == "" {
+		return "", ErrPathDisabled
+	}
+
+	root, err := filepath.Abs(demosFolderPath)
+	if err != nil {
+		return "", err
+	}
+
+	absolutePath := filepath.Join(root, filepath.FromSlash(demoPath))
+	relativePath, err := filepath.Rel(root, absolutePath)
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return "", ErrPathOutsideFolder
+	}
+
+	if stat, err := os.Stat(absolutePath); err != nil || stat.IsDir() {
+		return "", ErrNotFound
+	}
+
+	return absolutePath, nil
+}
@@ -0,0 +1,43 @@
+package demo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolvePath(t *testing.T) {
+	folderPath := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(folderPath, "day1"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(folderPath, "day1", "match.dem"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		folderPath    string
+		demoPath      string
+		expectedPath  string
+		expectedError error
+	}{
+		{folderPath, "day1/match.dem", filepath.Join(folderPath, "day1", "match.dem"), nil},
+		{folderPath, "day1/../day1/match.dem", filepath.Join(folderPath, "day1", "match.dem"), nil},
+		{"", "day1/match.dem", "", ErrPathDisabled},
+		{folderPath, "../match.dem", "", ErrPathOutsideFolder},
+		{folderPath, "day1/../../match.dem", "", ErrPathOutsideFolder},
+		{folderPath, "day1/missing.dem", "", ErrNotFound},
+		{folderPath, "day1", "", ErrNotFound},
+	}
+
+	for _, test := range tests {
+		path, err := ResolvePath(test.folderPath, test.demoPath)
+		if !errors.Is(err, test.expectedError) {
+			t.Errorf("%s: expected error %v, got %v", test.demoPath, test.expectedError, err)
+		}
+		if path != test.expectedPath {
+			t.Errorf("%s: expected path %q, got %q", test.demoPath, test.expectedPath, path)
+		}
+	}
+}
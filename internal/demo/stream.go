@@ -0,0 +1,292 @@
+package demo
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/akiver/cs-demo-analyzer/internal/filepath"
+	"github.com/klauspost/compress/zstd"
+)
+
+type compression string
+
+const (
+	compressionNone  compression = ""
+	compressionGzip  compression = "gzip"
+	compressionBzip2 compression = "bzip2"
+	compressionZstd  compression = "zstd"
+	compressionZip   compression = "zip"
+	compressionRar   compression = "rar"
+	compression7z    compression = "7z"
+)
+
+// Number of bytes required to detect the compression of a file.
+const magicBytesLength = 6
+
+// detectCompression returns the compression of a file from its first bytes.
+func detectCompression(magicBytes []byte) compression {
+	switch {
+	case bytes.HasPrefix(magicBytes, []byte{0x1f, 0x8b}):
+		return compressionGzip
+	case bytes.HasPrefix(magicBytes, []byte("BZh")):
+		return compressionBzip2
+	case bytes.HasPrefix(magicBytes, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return compressionZstd
+	case bytes.HasPrefix(magicBytes, []byte{'P', 'K', 0x03, 0x04}), bytes.HasPrefix(magicBytes, []byte{'P', 'K', 0x05, 0x06}):
+		return compressionZip
+	case bytes.HasPrefix(magicBytes, []byte("Rar!\x1a\x07")):
+		return compressionRar
+	case bytes.HasPrefix(magicBytes, []byte{'7', 'z', 0xbc, 0xaf, 0x27, 0x1c}):
+		return compression7z
+	default:
+		return compressionNone
+	}
+}
+
+// trimCompressionExtension returns the name of a compressed file without its compression extension, i.e.
+// "demo.dem.gz" becomes "demo.dem".
+func trimCompressionExtension(name string) string {
+	for _, extension := range []string{".gz", ".bz2", ".zst", ".zstd"} {
+		if strings.HasSuffix(strings.ToLower(name), extension) {
+			return name[:len(name)-len(extension)]
+		}
+	}
+
+	return name
+}
+
+// countingReader counts the number of bytes read from the underlying reader.
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+
+	return n, err
+}
+
+// Stream is a demo that is read sequentially from the beginning, it may come from a regular demo file, a compressed
+// demo or an archive.
+type Stream struct {
+	Demo   *Demo
+	reader io.Reader
+	// Set only when the demo is decompressed while reading, the demo size is known only once it has been entirely read.
+	counter *countingReader
+}
+
+func (stream *Stream) Read(p []byte) (int, error) {
+	return stream.reader.Read(p)
+}
+
+// ReadToEnd reads the remaining bytes of the demo.
+// It's required to get the checksum of compressed demos because their size is not known until they are entirely read.
+func (stream *Stream) ReadToEnd() error {
+	if stream.counter == nil {
+		return nil
+	}
+
+	if _, err := io.Copy(io.Discard, stream.reader); err != nil {
+		return err
+	}
+	stream.Demo.computeChecksum(stream.counter.count)
+
+	return nil
+}
+
+// newDecompressedStream reads the header of a demo that is decompressed while reading.
+// Because the reader can't be rewound, the bytes read to get the header are kept to be read again by the parser.
+func newDecompressedStream(reader io.Reader, modTime time.Time, matchInfoBytes []byte) (*Stream, error) {
+	counter := &countingReader{reader: reader}
+	var header bytes.Buffer
+	demo, err := readDemo(io.TeeReader(counter, &header), modTime, matchInfoBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Stream{
+		Demo:    demo,
+		reader:  io.MultiReader(&header, counter),
+		counter: counter,
+	}, nil
+}
+
+// ReadDemos calls fn for each demo contained in the file located at the given path.
+// The file may be a demo, a demo compressed with gzip, bzip2 or zstd, or a zip archive containing demos.
+// Compressed demos are decompressed while reading, their name and checksum describe the decompressed demo.
+func ReadDemos(demoPath string, fn func(stream *Stream) error) error {
+	file, err := os.Open(demoPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("demo file %q not found", demoPath)
+		}
+		return err
+	}
+	defer file.Close()
+
+	stats, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	magicBytes := make([]byte, magicBytesLength)
+	n, err := io.ReadFull(file, magicBytes)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	filePath := filepath.GetAbsoluteFilePath(demoPath)
+	// Path of the decompressed demo, used to find its .info file and its name.
+	innerDemoPath := trimCompressionExtension(demoPath)
+	var reader io.Reader
+	switch detectCompression(magicBytes[:n]) {
+	case compressionNone:
+		matchInfoBytes, matchInfoErr := getMatchInfoProtoBytes(demoPath)
+		demo, err := readDemo(file, stats.ModTime(), matchInfoBytes)
+		if err != nil {
+			return err
+		}
+		if matchInfoErr != nil {
+			demo.Warnings = append(demo.Warnings, matchInfoErr.Error())
+		}
+		demo.computeChecksum(stats.Size())
+		demo.FilePath = filePath
+		demo.FileName = filepath.GetFileNameWithoutExtension(demoPath)
+
+		if _, err = file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		return fn(&Stream{Demo: demo, reader: file})
+	case compressionGzip:
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	case compressionBzip2:
+		reader = bzip2.NewReader(file)
+	case compressionZstd:
+		decoder, err := zstd.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer decoder.Close()
+		reader = decoder
+	case compressionZip:
+		return readZipDemos(file, stats.Size(), filePath, fn)
+	default:
+		return fmt.Errorf("unsupported %s archive, only zip archives are supported", detectCompression(magicBytes[:n]))
+	}
+
+	matchInfoBytes, matchInfoErr := getMatchInfoProtoBytes(innerDemoPath)
+	stream, err := newDecompressedStream(reader, stats.ModTime(), matchInfoBytes)
+	if err != nil {
+		return err
+	}
+	if matchInfoErr != nil {
+		stream.Demo.Warnings = append(stream.Demo.Warnings, matchInfoErr.Error())
+	}
+	stream.Demo.FilePath = filePath
+	stream.Demo.FileName = filepath.GetFileNameWithoutExtension(innerDemoPath)
+
+	return fn(stream)
+}
+
+// readZipDemos calls fn for each .dem file contained in the zip archive.
+// The .info file associated with a demo is used if it's in the archive next to the demo.
+func readZipDemos(file *os.File, size int64, filePath string, fn func(stream *Stream) error) error {
+	archive, err := zip.NewReader(file, size)
+	if err != nil {
+		return err
+	}
+
+	filesByName := make(map[string]*zip.File)
+	for _, entry := range archive.File {
+		filesByName[entry.Name] = entry
+	}
+
+	demoCount := 0
+	fileNameCounts := make(map[string]int)
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() || !strings.EqualFold(path.Ext(entry.Name), ".dem") {
+			continue
+		}
+
+		var matchInfoBytes []byte
+		if infoEntry, exists := filesByName[entry.Name+".info"]; exists {
+			matchInfoBytes, err = readZipFile(infoEntry)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = readZipDemo(entry, filePath, zipDemoFileName(entry.Name, fileNameCounts), matchInfoBytes, fn)
+		if err != nil {
+			return err
+		}
+		demoCount++
+	}
+
+	if demoCount == 0 {
+		return fmt.Errorf("no demos found in the archive %q", filePath)
+	}
+
+	return nil
+}
+
+// zipDemoFileName returns the file name of a demo of an archive, the folders of the archive are kept in the name to
+// not export the demos of different folders with the same name, i.e. "map1/match.dem" becomes "map1_match".
+// A suffix is added if the archive contains several entries with the same name.
+func zipDemoFileName(entryName string, fileNameCounts map[string]int) string {
+	fileName := strings.TrimSuffix(strings.Trim(entryName, "/"), path.Ext(entryName))
+	fileName = strings.ReplaceAll(fileName, "/", "_")
+	fileNameCounts[fileName]++
+	if count := fileNameCounts[fileName]; count > 1 {
+		fileName = fmt.Sprintf("%s_%d", fileName, count)
+	}
+
+	return fileName
+}
+
+func readZipDemo(entry *zip.File, filePath string, fileName string, matchInfoBytes []byte, fn func(stream *Stream) error) error {
+	reader, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	stream, err := newDecompressedStream(reader, entry.Modified, matchInfoBytes)
+	if err != nil {
+		return err
+	}
+	stream.Demo.FilePath = filePath
+	stream.Demo.FileName = fileName
+
+	return fn(stream)
+}
+
+func readZipFile(entry *zip.File) ([]byte, error) {
+	reader, err := entry.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
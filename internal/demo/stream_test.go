@@ -0,0 +1,118 @@
+package demo
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// buildSource1Demo returns a fake CSGO demo, only its header is valid.
+func buildSource1Demo() []byte {
+	var buffer bytes.Buffer
+	buffer.WriteString("HL2DEMO\x00")
+	binary.Write(&buffer, binary.LittleEndian, int32(4))     // demo protocol
+	binary.Write(&buffer, binary.LittleEndian, int32(13881)) // network protocol
+	for _, value := range []string{"server", "GOTV Demo", "de_dust2", "csgo"} {
+		field := make([]byte, 260)
+		copy(field, value)
+		buffer.Write(field)
+	}
+	binary.Write(&buffer, binary.LittleEndian, float32(2400)) // duration
+	binary.Write(&buffer, binary.LittleEndian, int32(153600)) // ticks
+	binary.Write(&buffer, binary.LittleEndian, int32(76800))  // frames
+	binary.Write(&buffer, binary.LittleEndian, int32(1024))   // signon length
+	buffer.Write(bytes.Repeat([]byte{0xab}, 64*1024))
+
+	return buffer.Bytes()
+}
+
+func writeFile(t *testing.T, filePath string, write func(file *os.File) error) {
+	file, err := os.Create(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if err = write(file); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetDemosFromCompressedFiles(t *testing.T) {
+	folder := t.TempDir()
+	demoBytes := buildSource1Demo()
+	demoPath := filepath.Join(folder, "match.dem")
+	if err := os.WriteFile(demoPath, demoBytes, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedDemo, err := GetDemoFromPath(demoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, demoPath+".gz", func(file *os.File) error {
+		writer := gzip.NewWriter(file)
+		writer.Write(demoBytes)
+		return writer.Close()
+	})
+	writeFile(t, demoPath+".zst", func(file *os.File) error {
+		writer, err := zstd.NewWriter(file)
+		if err != nil {
+			return err
+		}
+		writer.Write(demoBytes)
+		return writer.Close()
+	})
+	writeFile(t, filepath.Join(folder, "series.zip"), func(file *os.File) error {
+		writer := zip.NewWriter(file)
+		for _, name := range []string{"map1/match.dem", "map2/match.dem", "map2/match.dem"} {
+			entry, err := writer.Create(name)
+			if err != nil {
+				return err
+			}
+			entry.Write(demoBytes)
+		}
+		return writer.Close()
+	})
+
+	// Demos of an archive keep their folder in their name to not overwrite the export of each other.
+	samples := map[string][]string{
+		demoPath + ".gz":                    {expectedDemo.FileName},
+		demoPath + ".zst":                   {expectedDemo.FileName},
+		filepath.Join(folder, "series.zip"): {"map1_match", "map2_match", "map2_match_2"},
+	}
+
+	for filePath, expectedFileNames := range samples {
+		demos, err := GetDemosFromPath(filePath)
+		if err != nil {
+			t.Fatalf("%s: %v", filePath, err)
+		}
+
+		if len(demos) != len(expectedFileNames) {
+			t.Fatalf("%s: expected %d demos got %d", filePath, len(expectedFileNames), len(demos))
+		}
+
+		for index, demo := range demos {
+			if demo.Checksum != expectedDemo.Checksum {
+				t.Errorf("%s: expected checksum %s got %s", filePath, expectedDemo.Checksum, demo.Checksum)
+			}
+			if demo.FileName != expectedFileNames[index] {
+				t.Errorf("%s: expected file name %s got %s", filePath, expectedFileNames[index], demo.FileName)
+			}
+			if demo.MapName != expectedDemo.MapName {
+				t.Errorf("%s: expected map %s got %s", filePath, expectedDemo.MapName, demo.MapName)
+			}
+		}
+	}
+
+	if _, err = GetDemoFromPath(filepath.Join(folder, "series.zip")); err == nil {
+		t.Error("expected an error when getting a single demo from an archive that contains several demos")
+	}
+}
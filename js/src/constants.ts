@@ -58,9 +58,35 @@ export const ExportFormat = {
   CSV: 'csv',
   JSON: 'json',
   CSDM: 'csdm', // Special CSV export dedicated to the application CS Demo Manager
+  NDJSON: 'ndjson', // One JSON object per line, written while the demo is being analyzed
+  PARQUET: 'parquet', // One typed Parquet file per entity
+  SQLITE: 'sqlite', // SQLite database that may contain several matches
+  POSTGRES: 'postgres', // PostgreSQL schema, COPY files and load script
+  PROTOBUF: 'protobuf', // Length-delimited protobuf records described by proto/csda/v1/match.proto
 } as const;
 export type ExportFormat = (typeof ExportFormat)[keyof typeof ExportFormat];
 
+export const Compression = {
+  Gzip: 'gzip',
+  Zstd: 'zstd',
+} as const;
+export type Compression = (typeof Compression)[keyof typeof Compression];
+
+export const EventCategory = {
+  Kills: 'kills',
+  Damages: 'damages',
+  Shots: 'shots',
+  Grenades: 'grenades',
+  Bombs: 'bombs',
+  Positions: 'positions',
+  Chickens: 'chickens',
+  Hostages: 'hostages',
+  Chat: 'chat',
+  Economy: 'economy',
+  Clutches: 'clutches',
+} as const;
+export type EventCategory = (typeof EventCategory)[keyof typeof EventCategory];
+
 export const TeamNumber = {
   UNASSIGNED: 0,
   SPECTATOR: 1,
@@ -368,3 +394,14 @@ export const GameMode = {
   Survival: 'survival',
 } as const;
 export type GameMode = (typeof GameMode)[keyof typeof GameMode];
+
+// Exit code of the CLI, it depends on the error that stopped the analysis.
+export const ExitCode = {
+  Success: 0,
+  Error: 1,
+  InvalidArgs: 2,
+  UnknownSource: 3,
+  UnsupportedDemo: 4,
+  MissingGameEventDescriptors: 5,
+} as const;
+export type ExitCode = (typeof ExitCode)[keyof typeof ExitCode];
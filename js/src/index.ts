@@ -1,7 +1,15 @@
 import { exec } from 'node:child_process';
 import fs from 'node:fs/promises';
 import { getBinaryPath } from './platform';
-import { DemoSource, ExportFormat } from './constants';
+import { Compression, DemoSource, EventCategory, ExportFormat } from './constants';
+
+export type AnalyzeProgress = {
+  demoFileName: string;
+  tick: number;
+  frame: number;
+  roundNumber: number;
+  progress: number; // between 0 and 1
+};
 
 export type Options = {
   demoPath: string;
@@ -9,9 +17,15 @@ export type Options = {
   format: ExportFormat;
   source?: DemoSource;
   analyzePositions?: boolean;
+  include?: EventCategory[];
+  exclude?: EventCategory[];
   minify?: boolean; // JSON only
+  compress?: Compression; // JSON, CSV and CSDM only
+  contentHash?: boolean; // SHA-256 of the whole demo, slower than the default checksum
+  cacheFolderPath?: string; // Analyzed matches are read from this folder instead of analyzing the demo again
   onStart?: (command: string) => void;
   onStdout?: (data: string) => void;
+  onProgress?: (progress: AnalyzeProgress) => void;
   onStderr?: (data: string) => void;
   onEnd?: (exitCode: number) => void;
   executablePath?: string;
@@ -23,9 +37,15 @@ export async function analyzeDemo({
   format,
   source,
   analyzePositions,
+  include,
+  exclude,
   minify,
+  compress,
+  contentHash,
+  cacheFolderPath,
   onStart,
   onStdout,
+  onProgress,
   onStderr,
   onEnd,
   executablePath,
@@ -46,9 +66,27 @@ export async function analyzeDemo({
     if (analyzePositions) {
       args.push(`-positions="${analyzePositions}"`);
     }
+    if (include && include.length > 0) {
+      args.push(`-include="${include.join(',')}"`);
+    }
+    if (exclude && exclude.length > 0) {
+      args.push(`-exclude="${exclude.join(',')}"`);
+    }
     if (minify) {
       args.push('-minify');
     }
+    if (compress) {
+      args.push(`-compress="${compress}"`);
+    }
+    if (contentHash) {
+      args.push('-content-hash');
+    }
+    if (cacheFolderPath) {
+      args.push(`-cache-dir="${cacheFolderPath}"`);
+    }
+    if (onProgress) {
+      args.push('-progress=json');
+    }
     const command = args.join(' ');
     if (onStart) {
       onStart(command);
@@ -61,6 +99,25 @@ export async function analyzeDemo({
       });
     }
 
+    if (onProgress) {
+      // Progress lines are JSON objects, a chunk of data may contain several lines or a partial line.
+      let pendingData = '';
+      child.stdout?.on('data', (data: string) => {
+        const lines = (pendingData + data).split('\n');
+        pendingData = lines.pop() ?? '';
+        for (const line of lines) {
+          if (!line.startsWith('{')) {
+            continue;
+          }
+          try {
+            onProgress(JSON.parse(line) as AnalyzeProgress);
+          } catch {
+            // Not a progress line
+          }
+        }
+      });
+    }
+
     if (onStderr) {
       child.stderr?.on('data', (data: string) => {
         onStderr(data);
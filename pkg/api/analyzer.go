@@ -1,10 +1,16 @@
 package api
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash"
+	"io"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/akiver/cs-demo-analyzer/internal/converters"
 	d "github.com/akiver/cs-demo-analyzer/internal/demo"
@@ -58,43 +64,132 @@ type Analyzer struct {
 	// to detect which player is untying an hostage in case of consecutive events.
 	playersUntyingAnHostage map[uint64]int
 	chickenEntities         []st.Entity
+	sink                    EventSink
+	// When true, events that are not required to compute stats are only sent to the sink and not kept in the match.
+	discardEvents   bool
+	eventCategories map[constants.EventCategory]bool
+	// Indicates if positions have to be recorded at the current frame.
+	shouldSamplePositions func() bool
+	positionsAliveOnly    bool
+	logger                DiagnosticLogger
+	// Error that stopped the parsing, returned instead of the parser error.
+	parsingErr error
 }
 
 type AnalyzeDemoOptions struct {
 	IncludePositions bool
+	// Controls how often and when positions are recorded, it has effect only when positions are included.
+	PositionSampling PositionSampling
 	Source           constants.DemoSource
+	// Categories of events to collect, all categories except positions are collected if empty.
+	// Events used to compute stats (kills, damages, shots, clutches, buys, economies, bombs planted/defused/exploded and
+	// hostages rescued) are always kept in the match so stats don't change, they are only not sent to EventSink nor
+	// exported.
+	IncludeEvents []constants.EventCategory
+	// Categories of events to not collect, it takes precedence over IncludeEvents and IncludePositions.
+	ExcludeEvents []constants.EventCategory
+	// Content of the .info file associated with the demo, used only by AnalyzeDemoFromReader.
+	// When analyzing a demo from a path, the .info file next to the demo is used if it exists.
+	MatchInfo []byte
+	// Called periodically during the analysis, at most once per ProgressInterval (DefaultProgressInterval if not set).
+	Progress         func(progress AnalyzeProgress)
+	ProgressInterval time.Duration
+	// Receives the objects built by the analyzer as soon as they are created.
+	EventSink EventSink
+	// Do not keep in the match the events sent to EventSink, except the ones required to compute stats: kills, damages,
+	// shots, clutches, buys, economies, bombs planted/defused/exploded and hostages rescued.
+	// Useful to analyze demos with positions without keeping millions of positions in memory.
+	DiscardEvents bool
+	// Receives the diagnostics as soon as they are detected, they are available in Match.Diagnostics too.
+	Logger DiagnosticLogger
+	// Compute the SHA-256 hash of the demo content while it's parsed, it's available in Match.ContentHash.
+	// The hash of compressed demos is the hash of the decompressed demo.
+	ComputeContentHash bool
 }
 
-func analyzeDemo(demoPath string, options AnalyzeDemoOptions) (*Match, error) {
+// analyzeDemos calls fn with the match of each demo contained in the file located at the given path.
+// The file may be a compressed demo or an archive containing several demos, see demo.ReadDemos.
+func analyzeDemos(ctx context.Context, demoPath string, options AnalyzeDemoOptions, fn func(match *Match) error) error {
 	if options.Source != "" {
 		err := ValidateDemoSource(options.Source)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 
-	demo, err := d.GetDemoFromPath(demoPath)
+	return d.ReadDemos(demoPath, func(stream *d.Stream) error {
+		match, err := analyzeDemoStream(ctx, stream, stream.Demo, options)
+		if err != nil {
+			return err
+		}
+
+		// The checksum of compressed demos is available only once the demo has been entirely read.
+		err = stream.ReadToEnd()
+		if err != nil {
+			return err
+		}
+		match.Checksum = stream.Demo.Checksum
+
+		return fn(match)
+	})
+}
+
+func analyzeDemo(ctx context.Context, demoPath string, options AnalyzeDemoOptions) (*Match, error) {
+	var matches []*Match
+	err := analyzeDemos(ctx, demoPath, options, func(match *Match) error {
+		if len(matches) > 0 {
+			return fmt.Errorf("the archive %q contains several demos, use AnalyzeDemos to analyze all of them", demoPath)
+		}
+		matches = append(matches, match)
+
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	file, err := os.Open(demoPath)
-	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return nil, fmt.Errorf("demo file %q not found", demoPath)
+	return matches[0], nil
+}
+
+func analyzeDemoFromReader(ctx context.Context, reader io.ReadSeeker, name string, options AnalyzeDemoOptions) (*Match, error) {
+	if options.Source != "" {
+		err := ValidateDemoSource(options.Source)
+		if err != nil {
+			return nil, err
 		}
+	}
+
+	demo, err := d.GetDemoFromReader(reader, name, options.MatchInfo)
+	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
+
+	return analyzeDemoStream(ctx, reader, demo, options)
+}
+
+// analyzeDemoStream parses the demo from the reader which must be positioned at the beginning of the demo.
+// The parsing is cancelled when ctx is done, the match built so far is available in the returned CancelledError.
+func analyzeDemoStream(ctx context.Context, reader io.Reader, demo *d.Demo, options AnalyzeDemoOptions) (*Match, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &CancelledError{Err: err}
+	}
 
 	parserConfig := dem.DefaultParserConfig
 	parserConfig.NetMessageDecryptionKey = demo.NetMessageDecryptionPublicKey
 	parserConfig.DisableMimicSource1Events = demo.Type == constants.DemoTypePOV
 
-	parser := dem.NewParserWithConfig(file, parserConfig)
+	// The demo is hashed while the parser reads it to not read it twice.
+	var contentHash hash.Hash
+	if options.ComputeContentHash {
+		contentHash = sha256.New()
+		reader = io.TeeReader(reader, contentHash)
+	}
+
+	demoReader := &progressReader{reader: reader, size: demo.Size}
+	parser := dem.NewParserWithConfig(demoReader, parserConfig)
 	defer parser.Close()
 
-	_, err = parser.ParseHeader()
+	_, err := parser.ParseHeader()
 	if err != nil {
 		return nil, err
 	}
@@ -105,10 +200,23 @@ func analyzeDemo(demoPath string, options AnalyzeDemoOptions) (*Match, error) {
 	}
 
 	if demo.IsSource2() && demo.Type == constants.DemoTypePOV {
-		return nil, errors.New("cs2 pov demos are not supported (CS2POVDemosNotSupported)")
+		return nil, &UnsupportedDemoError{Source: source, Game: constants.CS2, Type: constants.DemoTypePOV}
+	}
+
+	eventCategories, err := resolveEventCategories(options.IncludeEvents, options.ExcludeEvents, options.IncludePositions)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ValidatePositionSampling(options.PositionSampling)
+	if err != nil {
+		return nil, err
 	}
 
 	match := newMatch(source, demo)
+	if len(options.IncludeEvents) > 0 || len(options.ExcludeEvents) > 0 {
+		match.eventCategories = eventCategories
+	}
 
 	analyzer := &Analyzer{
 		parser:                    parser,
@@ -122,6 +230,15 @@ func analyzeDemo(demoPath string, options AnalyzeDemoOptions) (*Match, error) {
 		lastGrenadeThrownByPlayer: make(map[uint64]*Shot),
 		playersUntyingAnHostage:   make(map[uint64]int),
 		postProcess:               defaultPostProcess,
+		sink:                      options.EventSink,
+		discardEvents:             options.DiscardEvents,
+		eventCategories:           eventCategories,
+		positionsAliveOnly:        options.PositionSampling.AliveOnly,
+		logger:                    options.Logger,
+	}
+	analyzer.shouldSamplePositions = analyzer.newPositionSampler(options.PositionSampling)
+	if analyzer.sink == nil {
+		analyzer.sink = NopEventSink{}
 	}
 
 	analyzer.currentRound = &Round{
@@ -137,7 +254,14 @@ func analyzeDemo(demoPath string, options AnalyzeDemoOptions) (*Match, error) {
 		TeamBSide:          *match.TeamB.CurrentSide,
 	}
 
-	analyzer.registerCommonHandlers(options.IncludePositions)
+	for _, warning := range demo.Warnings {
+		analyzer.addDiagnostic(constants.DiagnosticSeverityWarning, constants.DiagnosticCodeInvalidMatchInfo, warning)
+	}
+
+	analyzer.registerCommonHandlers()
+	if options.Progress != nil {
+		analyzer.registerProgressHandler(demoReader, options)
+	}
 
 	switch source {
 	case constants.DemoSourceFaceIt:
@@ -157,14 +281,14 @@ func analyzeDemo(demoPath string, options AnalyzeDemoOptions) (*Match, error) {
 	case constants.DemoSourceEsportal:
 		createEsportalAnalyzer(analyzer)
 	case constants.DemoSourceCEVO:
-		return nil, errors.New("cevo demos are not supported (CevoNotSupported)")
+		return nil, &UnsupportedDemoError{Source: source}
 	case constants.DemoSourceFastcup:
 		createFastcupAnalyzer(analyzer)
 	case constants.DemoSourceFiveEPlay:
 		createFiveEPlayAnalyzer(analyzer)
 	case constants.DemoSourceGamersclub:
 		// Looks like they use an eBot fork but rounds are not detected properly.
-		return nil, errors.New("gamersclub demos are not supported (GamersClubNotSupported)")
+		return nil, &UnsupportedDemoError{Source: source}
 	case constants.DemoSourceMatchZy:
 		createMatchZyAnalyzer(analyzer)
 	case constants.DemoSourcePopFlash:
@@ -172,7 +296,7 @@ func analyzeDemo(demoPath string, options AnalyzeDemoOptions) (*Match, error) {
 		// Even latest CSGO demos from PopFlash may not really work because their recording system has probably changed
 		// and may not be compatible with the Valve one anymore (used to be the V2).
 		if demo.IsSource2() {
-			return nil, errors.New("cs2 PopFlash demos are not supported (PopFlashNotSupported)")
+			return nil, &UnsupportedDemoError{Source: source, Game: constants.CS2}
 		}
 		createValveAnalyzer(analyzer)
 	case constants.DemoSourceValve, constants.DemoSourcePerfectWorld, constants.DemoSourceESL:
@@ -180,17 +304,27 @@ func analyzeDemo(demoPath string, options AnalyzeDemoOptions) (*Match, error) {
 	case constants.DemoSourcePracc:
 		createPraccAnalyzer(analyzer)
 	default:
-		return nil, errors.New("unknown demo source, please specify the source with the -source flag (UnknownSource)")
+		return nil, ErrUnknownSource
 	}
 
+	stopCancellation := context.AfterFunc(ctx, parser.Cancel)
 	err = parser.ParseToEnd()
+	stopCancellation()
+	if analyzer.parsingErr != nil {
+		return nil, analyzer.parsingErr
+	}
+	isCancelled := errors.Is(err, dem.ErrCancelled) && ctx.Err() != nil
 	// Do not stop if the demo is corrupted, usually the error occurs at the end of the parsing.
 	// Depending on how far we were able to parse the demo we may still have data.
 	isCorruptedDemo := errors.Is(err, dem.ErrUnexpectedEndOfDemo)
-	if err != nil && !isCorruptedDemo {
+	if err != nil && !isCorruptedDemo && !isCancelled {
 		return nil, err
 	}
 
+	if isCorruptedDemo {
+		analyzer.addDiagnostic(constants.DiagnosticSeverityError, constants.DiagnosticCodeCorruptedDemo, err.Error())
+	}
+
 	// Required for CS2 demos, the following data are available only at the end of the parsing in the CDemoFileInfo message.
 	// The parser updates the header values when the CDemoFileInfo message is parsed.
 	// Unfortunately, some CS2 demos may not contain this message and so the header values are not updated.
@@ -214,26 +348,117 @@ func analyzeDemo(demoPath string, options AnalyzeDemoOptions) (*Match, error) {
 	}
 
 	analyzer.postProcess(analyzer)
+	if !match.isGameModeKnown() {
+		analyzer.addDiagnostic(
+			constants.DiagnosticSeverityWarning,
+			constants.DiagnosticCodeUnknownGameMode,
+			fmt.Sprintf("Unknown game mode string for game type: %d and game mode: %d", match.GameType, match.GameMode),
+		)
+	}
 	match.deleteIncompleteRounds()
 	match.computeResultStats()
+	analyzer.sendRoundEnd(analyzer.currentRound)
+
+	if isCancelled {
+		return nil, &CancelledError{Err: ctx.Err(), Match: &match}
+	}
+
+	if contentHash != nil {
+		// The parser stops at the end of the demo messages, remaining bytes must be hashed too.
+		_, err = io.Copy(io.Discard, reader)
+		if err != nil {
+			return nil, err
+		}
+		match.ContentHash = hex.EncodeToString(contentHash.Sum(nil))
+	}
+
+	if options.Progress != nil {
+		progress := analyzer.buildProgress(demoReader)
+		progress.Progress = 1
+		options.Progress(progress)
+	}
 
 	return &match, nil
 }
 
+// AnalyzeDemo analyzes the demo located at the given path, the demo may be compressed with gzip, bzip2 or zstd or be
+// inside a zip archive.
+// It returns an error if the file is an archive that contains several demos, use AnalyzeDemos in this case.
 func AnalyzeDemo(demoPath string, options AnalyzeDemoOptions) (*Match, error) {
-	match, err := analyzeDemo(demoPath, options)
+	match, err := analyzeDemo(context.Background(), demoPath, options)
+
+	return match, err
+}
+
+// AnalyzeDemoContext is like AnalyzeDemo but the analysis is stopped when ctx is done.
+// In this case a *CancelledError is returned, it contains the match built until the analysis was stopped.
+func AnalyzeDemoContext(ctx context.Context, demoPath string, options AnalyzeDemoOptions) (*Match, error) {
+	match, err := analyzeDemo(ctx, demoPath, options)
+
+	return match, err
+}
+
+// AnalyzeDemos analyzes all demos contained in the file located at the given path, i.e. a zip archive containing the
+// demos of a series. It returns a single match when the file is a demo or a compressed demo.
+func AnalyzeDemos(demoPath string, options AnalyzeDemoOptions) ([]*Match, error) {
+	var matches []*Match
+	err := analyzeDemos(context.Background(), demoPath, options, func(match *Match) error {
+		matches = append(matches, match)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+// AnalyzeDemoFromReader analyzes a demo that is not available as a file, i.e. in memory or from an object storage.
+// name is used as the demo file path and must end with the demo file name (i.e. "path/to/demo.dem").
+// Since there is no .info file next to the demo, its content may be provided with the MatchInfo option.
+func AnalyzeDemoFromReader(reader io.ReadSeeker, name string, options AnalyzeDemoOptions) (*Match, error) {
+	match, err := analyzeDemoFromReader(context.Background(), reader, name, options)
+
+	return match, err
+}
+
+// AnalyzeDemoFromReaderContext is like AnalyzeDemoFromReader but the analysis is stopped when ctx is done, see
+// AnalyzeDemoContext.
+func AnalyzeDemoFromReaderContext(ctx context.Context, reader io.ReadSeeker, name string, options AnalyzeDemoOptions) (*Match, error) {
+	match, err := analyzeDemoFromReader(ctx, reader, name, options)
 
 	return match, err
 }
 
 type AnalyzeAndExportDemoOptions struct {
 	IncludePositions bool
+	PositionSampling PositionSampling
 	Source           constants.DemoSource
+	// Export files of categories that are not collected are not written, see AnalyzeDemoOptions.
+	IncludeEvents    []constants.EventCategory
+	ExcludeEvents    []constants.EventCategory
 	Format           constants.ExportFormat
 	MinifyJSON       bool
+	Compression      constants.Compression // JSON, CSV and CSDM only, the compression extension is added to file names
+	Progress         func(progress AnalyzeProgress)
+	ProgressInterval time.Duration
+	Logger           DiagnosticLogger
+	// Compute the SHA-256 hash of the demo content, see AnalyzeDemoOptions.
+	ComputeContentHash bool
+	// Folder of the analysis cache, analyzed matches are written into it and read from it instead of analyzing the
+	// demos again when the csda version and the analysis options are the same. Exports to NDJSON don't use the cache
+	// because events are not kept in memory. The cache is disabled if empty, see ClearCache.
+	CacheFolderPath string
 }
 
 func AnalyzeAndExportDemo(demoPath string, outputPath string, options AnalyzeAndExportDemoOptions) error {
+	return AnalyzeAndExportDemoContext(context.Background(), demoPath, outputPath, options)
+}
+
+// AnalyzeAndExportDemoContext is like AnalyzeAndExportDemo but the analysis is stopped when ctx is done.
+// In this case nothing is exported and a *CancelledError is returned.
+func AnalyzeAndExportDemoContext(ctx context.Context, demoPath string, outputPath string, options AnalyzeAndExportDemoOptions) error {
 	var err error
 	if options.Format != "" {
 		err = ValidateExportFormat(options.Format)
@@ -242,22 +467,93 @@ func AnalyzeAndExportDemo(demoPath string, outputPath string, options AnalyzeAnd
 		}
 	}
 
-	match, err := analyzeDemo(demoPath, AnalyzeDemoOptions{
-		IncludePositions: options.IncludePositions,
-		Source:           options.Source,
-	})
-
+	err = ValidateCompression(options.Compression, options.Format)
 	if err != nil {
 		return err
 	}
 
-	switch options.Format {
-	case "csv":
-		err = exportMatchToCSV(match, outputPath)
-	case "json":
-		err = exportMatchToJSON(match, outputPath, options.MinifyJSON)
-	case "csdm":
-		err = exportMatchForCSDM(match, outputPath)
+	// CSV and CSDM exports produce several files, they are written as a tar stream on the standard output.
+	var tar *tarStream
+	if outputPath == StdoutOutputPath {
+		err = ValidateStdoutExportFormat(options.Format)
+		if err != nil {
+			return err
+		}
+
+		if options.Format == constants.ExportFormatCSV || options.Format == constants.ExportFormatCSDM {
+			tar = newTarStream(os.Stdout)
+		}
+	}
+
+	analyzeOptions := AnalyzeDemoOptions{
+		IncludePositions:   options.IncludePositions,
+		PositionSampling:   options.PositionSampling,
+		Source:             options.Source,
+		IncludeEvents:      options.IncludeEvents,
+		ExcludeEvents:      options.ExcludeEvents,
+		Progress:           options.Progress,
+		ProgressInterval:   options.ProgressInterval,
+		Logger:             options.Logger,
+		ComputeContentHash: options.ComputeContentHash,
+	}
+
+	// The NDJSON export is written while the demo is being analyzed, events don't have to be kept in memory.
+	var ndjson *ndjsonExporter
+	if options.Format == constants.ExportFormatNDJSON {
+		ndjson, err = newNDJSONExporter()
+		if err != nil {
+			return err
+		}
+		defer ndjson.close()
+
+		analyzeOptions.EventSink = ndjson
+		analyzeOptions.DiscardEvents = true
+	}
+
+	analyze := func(fn func(match *Match) error) error {
+		return analyzeDemos(ctx, demoPath, analyzeOptions, fn)
+	}
+	if options.CacheFolderPath != "" && ndjson == nil {
+		cache := newAnalysisCache(options.CacheFolderPath, analyzeOptions)
+		analyze = func(fn func(match *Match) error) error {
+			return cache.analyzeDemos(ctx, demoPath, fn)
+		}
+	}
+
+	// Each demo of an archive is exported separately, the export files are named after the demo.
+	err = analyze(func(match *Match) error {
+		switch options.Format {
+		case "csv":
+			output, err := newExportOutput(outputPath, options.Compression, tar)
+			if err != nil {
+				return err
+			}
+			return exportMatchToCSV(match, output)
+		case "json":
+			return exportMatchToJSON(match, outputPath, options.MinifyJSON, options.Compression)
+		case "csdm":
+			output, err := newExportOutput(outputPath, options.Compression, tar)
+			if err != nil {
+				return err
+			}
+			return exportMatchForCSDM(match, output)
+		case "ndjson":
+			return ndjson.export(match, outputPath)
+		case "parquet":
+			return exportMatchToParquet(match, outputPath)
+		case "sqlite":
+			return exportMatchToSQLite(match, outputPath)
+		case "postgres":
+			return exportMatchToPostgres(match, outputPath)
+		case "protobuf":
+			return exportMatchToProtobuf(match, outputPath)
+		}
+
+		return nil
+	})
+
+	if tar != nil {
+		err = errors.Join(err, tar.close())
 	}
 
 	return err
@@ -277,6 +573,7 @@ func (analyzer *Analyzer) reset() {
 	analyzer.clutch1 = nil
 	analyzer.clutch2 = nil
 	analyzer.match.reset()
+	analyzer.sink.OnMatchReset()
 	analyzer.updateTeamNames()
 	for _, player := range analyzer.match.PlayersBySteamID {
 		player.reset()
@@ -300,9 +597,19 @@ func (analyzer *Analyzer) reset() {
 
 func (analyzer *Analyzer) resetCurrentRound() {
 	analyzer.match.resetRound(analyzer.currentRound.Number)
+	analyzer.sink.OnRoundReset(analyzer.currentRound.Number)
 	analyzer.createPlayersEconomies()
 }
 
+// addShot keeps the shot in the match even with the DiscardEvents option because shots are used to compute the
+// accuracy of players and the shot count of the match.
+func (analyzer *Analyzer) addShot(shot *Shot) {
+	analyzer.match.Shots = append(analyzer.match.Shots, shot)
+	if analyzer.collects(constants.EventCategoryShots) {
+		analyzer.sink.OnShot(shot)
+	}
+}
+
 func (analyzer *Analyzer) registerPlayer(player *common.Player, teamState *common.TeamState) {
 	match := analyzer.match
 	if _, alreadyExists := match.PlayersBySteamID[player.SteamID64]; alreadyExists {
@@ -429,6 +736,7 @@ func (analyzer *Analyzer) createRound() {
 	analyzer.playersUntyingAnHostage = map[uint64]int{}
 	roundNumber := analyzer.currentRound.Number + 1
 
+	analyzer.sendRoundEnd(analyzer.currentRound)
 	analyzer.currentRound = newRound(roundNumber, analyzer)
 	analyzer.createPlayersEconomies()
 }
@@ -551,7 +859,7 @@ func (analyzer *Analyzer) defaultAnnouncementWinPanelMatchHandler(event events.A
 	analyzer.updatePlayersScores()
 }
 
-func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
+func (analyzer *Analyzer) registerCommonHandlers() {
 	parser := analyzer.parser
 	match := analyzer.match
 
@@ -591,17 +899,17 @@ func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
 	})
 
 	parser.RegisterEventHandler(func(event events.OtherDeath) {
-		if event.OtherType != "chicken" {
+		if event.OtherType != "chicken" || !analyzer.collects(constants.EventCategoryChickens) {
 			return
 		}
 
 		if event.Killer == nil {
-			fmt.Println(analyzer.currentTick(), "A chicken has been killed but the killer is nil")
+			analyzer.addDiagnostic(constants.DiagnosticSeverityInfo, constants.DiagnosticCodeMissingPlayer, "A chicken has been killed but the killer is nil")
 			return
 		}
 
 		chickenDeath := newChickenDeath(analyzer.parser.CurrentFrame(), analyzer.currentTick(), analyzer.currentRound.Number, event.Killer.SteamID64, equipmentToWeaponName[event.Weapon.Type])
-		analyzer.match.ChickenDeaths = append(analyzer.match.ChickenDeaths, chickenDeath)
+		addEvent(analyzer, &analyzer.match.ChickenDeaths, chickenDeath, analyzer.sink.OnChickenDeath)
 	})
 
 	parser.RegisterEventHandler(func(event events.ItemPickup) {
@@ -637,7 +945,11 @@ func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
 		}
 
 		currentRound.weaponsBoughtUniqueIds = append(currentRound.weaponsBoughtUniqueIds, event.Weapon.UniqueID2().String())
-		match.PlayersBuy = append(match.PlayersBuy, newPlayerBuy(analyzer, event))
+		buy := newPlayerBuy(analyzer, event)
+		match.PlayersBuy = append(match.PlayersBuy, buy)
+		if analyzer.collects(constants.EventCategoryEconomy) {
+			analyzer.sink.OnPlayerBuy(buy)
+		}
 	})
 
 	parser.RegisterEventHandler(func(event events.PlayerHurt) {
@@ -648,6 +960,9 @@ func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
 		damage := newDamageFromGameEvent(analyzer, event)
 		if damage != nil {
 			match.Damages = append(match.Damages, damage)
+			if analyzer.collects(constants.EventCategoryDamages) {
+				analyzer.sink.OnDamage(damage)
+			}
 		}
 	})
 
@@ -660,39 +975,52 @@ func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
 		}
 	})
 
-	if includePositions {
+	if analyzer.collects(constants.EventCategoryPositions) {
+		includeChickens := analyzer.collects(constants.EventCategoryChickens)
+		includeGrenades := analyzer.collects(constants.EventCategoryGrenades)
+		includeHostages := analyzer.collects(constants.EventCategoryHostages)
 		parser.RegisterEventHandler(func(event events.FrameDone) {
-			if !analyzer.matchStarted() {
+			if !analyzer.matchStarted() || !analyzer.shouldSamplePositions() {
 				return
 			}
 
-			for _, chickenEntity := range analyzer.chickenEntities {
-				chickenPosition := newChickenPositionFromEntity(analyzer, chickenEntity)
-				match.ChickenPositions = append(match.ChickenPositions, chickenPosition)
+			if includeChickens {
+				for _, chickenEntity := range analyzer.chickenEntities {
+					chickenPosition := newChickenPositionFromEntity(analyzer, chickenEntity)
+					addEvent(analyzer, &match.ChickenPositions, chickenPosition, analyzer.sink.OnChickenPosition)
+				}
 			}
 
-			for _, projectile := range parser.GameState().GrenadeProjectiles() {
-				position := newGrenadePositionFromProjectile(analyzer, projectile)
-				if position != nil {
-					match.GrenadePositions = append(match.GrenadePositions, position)
+			if includeGrenades {
+				for _, projectile := range parser.GameState().GrenadeProjectiles() {
+					position := newGrenadePositionFromProjectile(analyzer, projectile)
+					if position != nil {
+						addEvent(analyzer, &match.GrenadePositions, position, analyzer.sink.OnGrenadePosition)
+					}
 				}
-			}
 
-			for _, inferno := range parser.GameState().Infernos() {
-				infernoPosition := newInfernoPositionFromInferno(analyzer, inferno)
-				if infernoPosition != nil {
-					match.InfernoPositions = append(match.InfernoPositions, infernoPosition)
+				for _, inferno := range parser.GameState().Infernos() {
+					infernoPosition := newInfernoPositionFromInferno(analyzer, inferno)
+					if infernoPosition != nil {
+						addEvent(analyzer, &match.InfernoPositions, infernoPosition, analyzer.sink.OnInfernoPosition)
+					}
 				}
 			}
 
 			for _, player := range parser.GameState().Participants().Playing() {
+				if analyzer.positionsAliveOnly && !player.IsAlive() {
+					continue
+				}
+
 				playerPosition := newPlayerPosition(analyzer, player)
-				match.PlayerPositions = append(match.PlayerPositions, playerPosition)
+				addEvent(analyzer, &match.PlayerPositions, playerPosition, analyzer.sink.OnPlayerPosition)
 			}
 
-			for _, hostage := range parser.GameState().Hostages() {
-				hostagePosition := newHostagePositionFromHostage(analyzer, hostage)
-				match.HostagePositions = append(match.HostagePositions, hostagePosition)
+			if includeHostages {
+				for _, hostage := range parser.GameState().Hostages() {
+					hostagePosition := newHostagePositionFromHostage(analyzer, hostage)
+					addEvent(analyzer, &match.HostagePositions, hostagePosition, analyzer.sink.OnHostagePosition)
+				}
 			}
 		})
 	}
@@ -708,7 +1036,7 @@ func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
 
 	missingGameEventDescriptorsWarnCount := 0
 	parser.RegisterEventHandler(func(event events.ParserWarn) {
-		fmt.Println(event)
+		analyzer.addDiagnostic(constants.DiagnosticSeverityWarning, constants.DiagnosticCodeParserWarning, event.Message)
 		if event.Type != events.WarnTypeGameEventBeforeDescriptors {
 			return
 		}
@@ -721,7 +1049,9 @@ func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
 		// https://github.com/markus-wa/demoinfocs-golang/pull/460
 		missingGameEventDescriptorsWarnCount += 1
 		if missingGameEventDescriptorsWarnCount >= 20 {
-			panic(errors.New("missing game event descriptors (ErrMissingGameEventDescriptors)"))
+			// Panicking from a handler would lose the error type, the parsing is cancelled instead.
+			analyzer.parsingErr = ErrMissingGameEventDescriptors
+			parser.Cancel()
 		}
 	})
 
@@ -750,6 +1080,9 @@ func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
 		kill := newKillFromGameEvent(analyzer, event)
 		if kill != nil {
 			match.Kills = append(match.Kills, kill)
+			if analyzer.collects(constants.EventCategoryKills) {
+				analyzer.sink.OnKill(kill)
+			}
 		}
 
 		if analyzer.clutch1 != nil {
@@ -821,13 +1154,13 @@ func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
 	})
 
 	parser.RegisterEventHandler(func(event events.HeExplode) {
-		if !analyzer.matchStarted() {
+		if !analyzer.matchStarted() || !analyzer.collects(constants.EventCategoryGrenades) {
 			return
 		}
 
 		heGrenadeExplode := newHeGrenadeExplodeFromGameEvent(analyzer, event)
 		if heGrenadeExplode != nil {
-			match.HeGrenadesExplode = append(match.HeGrenadesExplode, heGrenadeExplode)
+			addEvent(analyzer, &match.HeGrenadesExplode, heGrenadeExplode, analyzer.sink.OnHeGrenadeExplode)
 		}
 	})
 
@@ -845,7 +1178,7 @@ func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
 			analyzer.lastGrenadeThrownByPlayer[shot.PlayerSteamID64] = shot
 		}
 
-		match.Shots = append(match.Shots, shot)
+		analyzer.addShot(shot)
 	})
 
 	parser.RegisterEventHandler(func(event events.BombPlanted) {
@@ -857,6 +1190,9 @@ func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
 
 		bombPlanted := newBombPlanted(analyzer, event)
 		match.BombsPlanted = append(match.BombsPlanted, bombPlanted)
+		if analyzer.collects(constants.EventCategoryBombs) {
+			analyzer.sink.OnBombPlanted(bombPlanted)
+		}
 	})
 
 	parser.RegisterEventHandler(func(event events.BombDefused) {
@@ -866,6 +1202,9 @@ func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
 
 		bombDefused := newBombDefused(analyzer, event)
 		match.BombsDefused = append(match.BombsDefused, bombDefused)
+		if analyzer.collects(constants.EventCategoryBombs) {
+			analyzer.sink.OnBombDefused(bombDefused)
+		}
 		analyzer.currentRound.EndReason = events.RoundEndReasonBombDefused
 	})
 
@@ -876,43 +1215,46 @@ func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
 
 		bombExploded := newBombExploded(analyzer, event)
 		match.BombsExploded = append(match.BombsExploded, bombExploded)
+		if analyzer.collects(constants.EventCategoryBombs) {
+			analyzer.sink.OnBombExploded(bombExploded)
+		}
 	})
 
 	parser.RegisterEventHandler(func(event events.BombPlantBegin) {
-		if !analyzer.matchStarted() {
+		if !analyzer.matchStarted() || !analyzer.collects(constants.EventCategoryBombs) {
 			return
 		}
 
 		bombPlantStart := newBombPlantStart(analyzer, event)
-		match.BombsPlantStart = append(match.BombsPlantStart, bombPlantStart)
+		addEvent(analyzer, &match.BombsPlantStart, bombPlantStart, analyzer.sink.OnBombPlantStart)
 	})
 
 	parser.RegisterEventHandler(func(event events.BombDefuseStart) {
-		if !analyzer.matchStarted() {
+		if !analyzer.matchStarted() || !analyzer.collects(constants.EventCategoryBombs) {
 			return
 		}
 
 		bombDefuseStart := newBombDefuseStart(analyzer, event.Player)
-		match.BombsDefuseStart = append(match.BombsDefuseStart, bombDefuseStart)
+		addEvent(analyzer, &match.BombsDefuseStart, bombDefuseStart, analyzer.sink.OnBombDefuseStart)
 	})
 
 	parser.RegisterEventHandler(func(event events.PlayerFlashed) {
-		if !analyzer.matchStarted() || event.Player == nil || event.Attacker == nil || event.Player.IsBot {
+		if !analyzer.matchStarted() || !analyzer.collects(constants.EventCategoryGrenades) || event.Player == nil || event.Attacker == nil || event.Player.IsBot {
 			return
 		}
 
 		playerFlashed := newPlayerFlashed(analyzer, event)
-		match.PlayersFlashed = append(match.PlayersFlashed, playerFlashed)
+		addEvent(analyzer, &match.PlayersFlashed, playerFlashed, analyzer.sink.OnPlayerFlashed)
 	})
 
 	parser.RegisterEventHandler(func(event events.FlashExplode) {
-		if !analyzer.matchStarted() {
+		if !analyzer.matchStarted() || !analyzer.collects(constants.EventCategoryGrenades) {
 			return
 		}
 
 		flashbangExplode := newFlashbangExplodeFromGameEvent(analyzer, event)
 		if flashbangExplode != nil {
-			match.FlashbangsExplode = append(match.FlashbangsExplode, flashbangExplode)
+			addEvent(analyzer, &match.FlashbangsExplode, flashbangExplode, analyzer.sink.OnFlashbangExplode)
 		}
 	})
 
@@ -923,23 +1265,23 @@ func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
 
 		projectile := event.Projectile
 		if projectile == nil {
-			fmt.Println("Projectile nil in grenade projectile throw event")
+			analyzer.addDiagnostic(constants.DiagnosticSeverityWarning, constants.DiagnosticCodeMissingGrenade, "Projectile nil in grenade projectile throw event")
 			return
 		}
 
 		thrower := projectile.Thrower
 		if thrower == nil {
-			fmt.Println("Thrower nil in grenade projectile throw event, falling back to owner")
+			analyzer.addDiagnostic(constants.DiagnosticSeverityInfo, constants.DiagnosticCodeMissingThrower, "Thrower nil in grenade projectile throw event, falling back to owner")
 			thrower = projectile.WeaponInstance.Owner
 			if thrower == nil {
-				fmt.Println("Owner nil in grenade projectile throw event")
+				analyzer.addDiagnostic(constants.DiagnosticSeverityWarning, constants.DiagnosticCodeMissingThrower, "Owner nil in grenade projectile throw event")
 				return
 			}
 		}
 
 		lastGrenadeShotExist := analyzer.lastGrenadeThrownByPlayer[thrower.SteamID64]
 		if lastGrenadeShotExist == nil {
-			fmt.Println("A projectile throw event occurred whereas its weapon fired event didn't occurred.")
+			analyzer.addDiagnostic(constants.DiagnosticSeverityWarning, constants.DiagnosticCodeUnmatchedProjectile, "A projectile throw event occurred whereas its weapon fired event didn't occurred.")
 		} else {
 			lastGrenadeShotExist.ProjectileID = projectile.UniqueID()
 			delete(analyzer.lastGrenadeThrownByPlayer, thrower.SteamID64)
@@ -947,46 +1289,46 @@ func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
 	})
 
 	parser.RegisterEventHandler(func(event events.GrenadeProjectileBounce) {
-		if !analyzer.matchStarted() {
+		if !analyzer.matchStarted() || !analyzer.collects(constants.EventCategoryGrenades) {
 			return
 		}
 
 		grenadeBounce := newGrenadeBounceFromProjectile(analyzer, event.Projectile)
 		if grenadeBounce != nil {
-			match.GrenadeBounces = append(match.GrenadeBounces, grenadeBounce)
+			addEvent(analyzer, &match.GrenadeBounces, grenadeBounce, analyzer.sink.OnGrenadeBounce)
 		}
 	})
 
 	parser.RegisterEventHandler(func(event events.GrenadeProjectileDestroy) {
-		if !analyzer.matchStarted() {
+		if !analyzer.matchStarted() || !analyzer.collects(constants.EventCategoryGrenades) {
 			return
 		}
 
 		grenadeProjectileDestroy := newGrenadeProjectileDestroyFromProjectile(analyzer, event.Projectile)
 		if grenadeProjectileDestroy != nil {
-			match.GrenadeProjectilesDestroy = append(match.GrenadeProjectilesDestroy, grenadeProjectileDestroy)
+			addEvent(analyzer, &match.GrenadeProjectilesDestroy, grenadeProjectileDestroy, analyzer.sink.OnGrenadeProjectileDestroy)
 		}
 	})
 
 	parser.RegisterEventHandler(func(event events.DecoyStart) {
-		if !analyzer.matchStarted() {
+		if !analyzer.matchStarted() || !analyzer.collects(constants.EventCategoryGrenades) {
 			return
 		}
 
 		decoyStart := newDecoyStartFromGameEvent(analyzer, event)
 		if decoyStart != nil {
-			match.DecoysStart = append(match.DecoysStart, decoyStart)
+			addEvent(analyzer, &match.DecoysStart, decoyStart, analyzer.sink.OnDecoyStart)
 		}
 	})
 
 	parser.RegisterEventHandler(func(event events.SmokeStart) {
-		if !analyzer.matchStarted() {
+		if !analyzer.matchStarted() || !analyzer.collects(constants.EventCategoryGrenades) {
 			return
 		}
 
 		smokeStart := newSmokeStartFromGameEvent(analyzer, event)
 		if smokeStart != nil {
-			match.SmokesStart = append(match.SmokesStart, smokeStart)
+			addEvent(analyzer, &match.SmokesStart, smokeStart, analyzer.sink.OnSmokeStart)
 		}
 	})
 
@@ -1007,7 +1349,7 @@ func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
 	})
 
 	parser.RegisterEventHandler(func(event events.HostageStateChanged) {
-		if !analyzer.matchStarted() {
+		if !analyzer.matchStarted() || !analyzer.collects(constants.EventCategoryHostages) {
 			return
 		}
 
@@ -1018,7 +1360,7 @@ func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
 				if player.IsGrabbingHostage() && playerHasNoUntyingInProgress {
 					analyzer.playersUntyingAnHostage[player.SteamID64] = event.Hostage.Entity.ID()
 					hostagePickedUpStart := newHostagePickupStart(analyzer, player, event.Hostage)
-					match.HostagePickUpStart = append(match.HostagePickUpStart, hostagePickedUpStart)
+					addEvent(analyzer, &match.HostagePickUpStart, hostagePickedUpStart, analyzer.sink.OnHostagePickUpStart)
 					break
 				}
 			}
@@ -1026,7 +1368,7 @@ func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
 			if event.Hostage.Leader() != nil {
 				delete(analyzer.playersUntyingAnHostage, event.Hostage.Leader().SteamID64)
 				hostagePickedUp := newHostagePickedUp(analyzer, event.Hostage)
-				match.HostagePickedUp = append(match.HostagePickedUp, hostagePickedUp)
+				addEvent(analyzer, &match.HostagePickedUp, hostagePickedUp, analyzer.sink.OnHostagePickedUp)
 			}
 		case common.HostageStateIdle:
 			// Case when a player started to untie an hostage and cancelled.
@@ -1051,6 +1393,9 @@ func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
 
 		hostageRescued := newHostageRescued(analyzer, event.Hostage)
 		match.HostageRescued = append(match.HostageRescued, hostageRescued)
+		if analyzer.collects(constants.EventCategoryHostages) {
+			analyzer.sink.OnHostageRescued(hostageRescued)
+		}
 	})
 
 	parser.RegisterEventHandler(func(event events.RoundMVPAnnouncement) {
@@ -1087,11 +1432,11 @@ func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
 	})
 
 	parser.RegisterEventHandler(func(event events.ChatMessage) {
-		if event.Sender == nil {
+		if event.Sender == nil || !analyzer.collects(constants.EventCategoryChat) {
 			return
 		}
 		chatMessage := newChatMessageFromGameEvent(analyzer, event)
-		match.ChatMessages = append(match.ChatMessages, chatMessage)
+		addEvent(analyzer, &match.ChatMessages, chatMessage, analyzer.sink.OnChatMessage)
 	})
 
 	// CS:GO only
@@ -1166,9 +1511,11 @@ func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
 
 	parser.RegisterEventHandler(func(event events.DataTablesParsed) {
 		serverClasses := parser.ServerClasses()
-		serverClasses.FindByName("CChicken").OnEntityCreated(func(entity st.Entity) {
-			analyzer.chickenEntities = append(analyzer.chickenEntities, entity)
-		})
+		if analyzer.collects(constants.EventCategoryPositions) && analyzer.collects(constants.EventCategoryChickens) {
+			serverClasses.FindByName("CChicken").OnEntityCreated(func(entity st.Entity) {
+				analyzer.chickenEntities = append(analyzer.chickenEntities, entity)
+			})
+		}
 
 		// We don't use the event TeamSideSwitch to detect teams switch because it's triggered several times at the same tick with POV demos.
 		var currentGamePhase common.GamePhase = common.GamePhaseInit
@@ -1258,6 +1605,9 @@ func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
 						},
 					})
 					match.BombsDefused = append(match.BombsDefused, bombDefused)
+					if analyzer.collects(constants.EventCategoryBombs) {
+						analyzer.sink.OnBombDefused(bombDefused)
+					}
 					analyzer.currentRound.EndReason = events.RoundEndReasonBombDefused
 				})
 			})
@@ -1332,7 +1682,7 @@ func (analyzer *Analyzer) registerCommonHandlers(includePositions bool) {
 						}
 
 						// TODO notImplemented Find a demo with a VAC live ban to get the correct value
-						fmt.Println("Match aborted with reason", reason)
+						analyzer.addDiagnostic(constants.DiagnosticSeverityWarning, constants.DiagnosticCodeMatchAborted, fmt.Sprintf("Match aborted with reason %v", reason))
 						analyzer.match.HasVacLiveBan = true
 					})
 				} else {
@@ -0,0 +1,364 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/akiver/cs-demo-analyzer/internal/demo"
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+)
+
+const modulePath = "github.com/akiver/cs-demo-analyzer"
+
+// Extension of the cache files, ClearCache removes only these files.
+const cacheFileExtension = ".csda"
+
+// Written at the beginning of cache files, it must be changed when the content of the files changes in a way that gob
+// can't decode, i.e. when the type of a field changes.
+const cacheFileHeader = "CSDACACHE1\n"
+
+// cacheEntry is the content of a cache file. The match is encoded with gob and compressed with zstd, values that are
+// not exported are stored separately and links between the match objects are restored when the entry is read.
+type cacheEntry struct {
+	Key             string
+	Match           *Match
+	GameModeStr     constants.GameModeStr
+	EventCategories map[constants.EventCategory]bool
+}
+
+// cacheKeyValues are the values that identify a match in the cache, the options of the analysis that change the match
+// are part of it.
+type cacheKeyValues struct {
+	Checksum           string
+	Version            string
+	MatchInfo          *DemoMatchInfo
+	Source             constants.DemoSource
+	IncludePositions   bool
+	PositionSampling   PositionSampling
+	IncludeEvents      []constants.EventCategory
+	ExcludeEvents      []constants.EventCategory
+	ComputeContentHash bool
+}
+
+// csdaVersion returns the version of cs-demo-analyzer the program has been built with, i.e. "v1.10.5".
+// Development builds use the VCS revision, or the hash of the executable when the sources have been modified, to not
+// read matches analyzed by a different code.
+var csdaVersion = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return executableHash()
+	}
+
+	module := &info.Main
+	for _, dependency := range info.Deps {
+		if dependency.Path == modulePath {
+			module = dependency
+		}
+	}
+	isLocalReplacement := module.Replace != nil && module.Replace.Version == ""
+	if module.Path == modulePath && module.Version != "" && module.Version != "(devel)" && !isLocalReplacement {
+		return module.Version
+	}
+
+	var revision string
+	var isModified bool
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			isModified = setting.Value == "true"
+		}
+	}
+	if revision != "" && !isModified {
+		return revision
+	}
+
+	return executableHash()
+})
+
+func executableHash() string {
+	executablePath, err := os.Executable()
+	if err != nil {
+		return "unknown"
+	}
+
+	file, err := os.Open(executablePath)
+	if err != nil {
+		return "unknown"
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "unknown"
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// analysisCache reads and writes analyzed matches in a folder, see AnalyzeAndExportDemoOptions.CacheFolderPath.
+type analysisCache struct {
+	folderPath string
+	options    AnalyzeDemoOptions
+}
+
+func newAnalysisCache(folderPath string, options AnalyzeDemoOptions) *analysisCache {
+	return &analysisCache{
+		folderPath: folderPath,
+		options:    options,
+	}
+}
+
+func sortedCategories(categories []constants.EventCategory) []constants.EventCategory {
+	categories = slices.Clone(categories)
+	slices.Sort(categories)
+
+	return slices.Compact(categories)
+}
+
+// key returns the key of the demo, the file name of its cache entry starts with its checksum.
+func (cache *analysisCache) key(demoInfo *DemoInfo) (string, error) {
+	values, err := json.Marshal(cacheKeyValues{
+		Checksum:           demoInfo.Checksum,
+		Version:            csdaVersion(),
+		MatchInfo:          demoInfo.MatchInfo,
+		Source:             cache.options.Source,
+		IncludePositions:   cache.options.IncludePositions,
+		PositionSampling:   cache.options.PositionSampling,
+		IncludeEvents:      sortedCategories(cache.options.IncludeEvents),
+		ExcludeEvents:      sortedCategories(cache.options.ExcludeEvents),
+		ComputeContentHash: cache.options.ComputeContentHash,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(values)
+
+	return demoInfo.Checksum + "_" + hex.EncodeToString(hash[:16]), nil
+}
+
+func (cache *analysisCache) filePath(key string) string {
+	return filepath.Join(cache.folderPath, key+cacheFileExtension)
+}
+
+// read returns the match of the key, nil if there is no valid entry for it.
+func (cache *analysisCache) read(key string) *Match {
+	file, err := os.Open(cache.filePath(key))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	header := make([]byte, len(cacheFileHeader))
+	if _, err := io.ReadFull(reader, header); err != nil || string(header) != cacheFileHeader {
+		return nil
+	}
+
+	decoder, err := zstd.NewReader(reader)
+	if err != nil {
+		return nil
+	}
+	defer decoder.Close()
+
+	var entry cacheEntry
+	if err := gob.NewDecoder(decoder).Decode(&entry); err != nil || entry.Key != key || entry.Match == nil {
+		return nil
+	}
+
+	return entry.restoreMatch()
+}
+
+// restoreMatch sets the values of the match that are not encoded.
+func (entry *cacheEntry) restoreMatch() *Match {
+	match := entry.Match
+	match.gameModeStr = entry.GameModeStr
+	match.eventCategories = entry.EventCategories
+
+	// Pointers to the same team are decoded as different teams.
+	findTeam := func(team *Team) *Team {
+		if team == nil {
+			return nil
+		}
+		for _, matchTeam := range []*Team{match.TeamA, match.TeamB} {
+			if matchTeam != nil && matchTeam.Letter == team.Letter {
+				return matchTeam
+			}
+		}
+		return team
+	}
+	match.Winner = findTeam(match.Winner)
+	if match.TeamA != nil && match.TeamB != nil {
+		match.scoreTeamA = &match.TeamA.Score
+		match.scoreTeamB = &match.TeamB.Score
+	}
+	for _, player := range match.PlayersBySteamID {
+		player.match = match
+		player.Team = findTeam(player.Team)
+	}
+
+	analyzer := &Analyzer{match: match}
+	for _, round := range match.Rounds {
+		round.analyzer = analyzer
+	}
+
+	return match
+}
+
+// write saves the match, the file is written under a temporary name and renamed to not leave an incomplete entry.
+func (cache *analysisCache) write(key string, match *Match) error {
+	err := os.MkdirAll(cache.folderPath, 0755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(cache.folderPath, key+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	if _, err := writer.WriteString(cacheFileHeader); err != nil {
+		return err
+	}
+
+	encoder, err := zstd.NewWriter(writer)
+	if err != nil {
+		return err
+	}
+
+	err = gob.NewEncoder(encoder).Encode(cacheEntry{
+		Key:             key,
+		Match:           match,
+		GameModeStr:     match.gameModeStr,
+		EventCategories: match.eventCategories,
+	})
+	if err != nil {
+		encoder.Close()
+		return err
+	}
+
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), cache.filePath(key))
+}
+
+// analyzeDemos is like the function analyzeDemos but the matches are read from the cache when all the demos of the
+// file have a valid entry. Otherwise the demos are analyzed and their matches are written into the cache.
+// Demos are read once more to compute their checksum, it only requires to read the header of uncompressed demos.
+func (cache *analysisCache) analyzeDemos(ctx context.Context, demoPath string, fn func(match *Match) error) error {
+	demos, err := demo.GetDemosFromPath(demoPath)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]string, len(demos))
+	var matches []*Match
+	for _, demoInfo := range demos {
+		key, err := cache.key(newDemoInfo(demoInfo))
+		if err != nil {
+			return err
+		}
+		keys[demoInfo.Checksum] = key
+
+		match := cache.read(key)
+		if match == nil {
+			continue
+		}
+		// The demo may have been renamed or moved.
+		match.DemoFilePath = demoInfo.FilePath
+		match.DemoFileName = demoInfo.FileName
+		matches = append(matches, match)
+	}
+
+	if len(matches) == len(demos) {
+		for _, match := range matches {
+			if err := ctx.Err(); err != nil {
+				return &CancelledError{Err: err}
+			}
+
+			if cache.options.Progress != nil {
+				cache.options.Progress(AnalyzeProgress{
+					DemoFileName: match.DemoFileName,
+					Tick:         match.TickCount,
+					RoundNumber:  len(match.Rounds),
+					Progress:     1,
+				})
+			}
+
+			// Diagnostics are logged during the parsing, log them again to not hide them when the demo is not parsed.
+			if cache.options.Logger != nil {
+				for _, diagnostic := range match.Diagnostics {
+					cache.options.Logger.LogDiagnostic(*diagnostic)
+				}
+			}
+
+			err := fn(match)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return analyzeDemos(ctx, demoPath, cache.options, func(match *Match) error {
+		key, ok := keys[match.Checksum]
+		if ok {
+			err := cache.write(key, match)
+			if err != nil {
+				return fmt.Errorf("failed to write the analysis cache: %w", err)
+			}
+		}
+
+		return fn(match)
+	})
+}
+
+// ClearCache removes the entries of the analysis cache located in the folder, other files are kept.
+func ClearCache(folderPath string) error {
+	entries, err := os.ReadDir(folderPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), cacheFileExtension) {
+			continue
+		}
+		errs = append(errs, os.Remove(filepath.Join(folderPath, entry.Name())))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+)
+
+func TestAnalysisCache(t *testing.T) {
+	match := newMatchWithOneOfEachEvent()
+	match.Checksum = "1"
+	match.Winner = match.TeamA
+	match.eventCategories = map[constants.EventCategory]bool{constants.EventCategoryKills: true}
+	expectedJSON, err := json.Marshal(match)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache := newAnalysisCache(t.TempDir(), AnalyzeDemoOptions{})
+	key, err := cache.key(&DemoInfo{Checksum: match.Checksum})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.write(key, match); err != nil {
+		t.Fatal(err)
+	}
+
+	cachedMatch := cache.read(key)
+	if cachedMatch == nil {
+		t.Fatal("cache entry not found")
+	}
+	if cachedMatch.Winner != cachedMatch.TeamA || cachedMatch.PlayersBySteamID[1].Team != cachedMatch.TeamA {
+		t.Error("teams are not shared between the match and its players")
+	}
+	if !cachedMatch.isEventCategoryExported(constants.EventCategoryKills) || cachedMatch.isEventCategoryExported(constants.EventCategoryShots) {
+		t.Error("event categories not restored")
+	}
+	cachedJSON, err := json.Marshal(cachedMatch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(cachedJSON, expectedJSON) {
+		t.Errorf("cached match differs from the analyzed match\nexpected: %s\ngot:      %s", expectedJSON, cachedJSON)
+	}
+
+	otherCache := newAnalysisCache(cache.folderPath, AnalyzeDemoOptions{IncludePositions: true})
+	otherKey, err := otherCache.key(&DemoInfo{Checksum: match.Checksum})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otherKey == key || otherCache.read(otherKey) != nil {
+		t.Error("cache entry read with different options")
+	}
+
+	otherFilePath := filepath.Join(cache.folderPath, "other.txt")
+	if err := os.WriteFile(otherFilePath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ClearCache(cache.folderPath); err != nil {
+		t.Fatal(err)
+	}
+	if cache.read(key) != nil {
+		t.Error("cache entry not removed")
+	}
+	if _, err := os.Stat(otherFilePath); err != nil {
+		t.Errorf("file that is not a cache entry removed: %v", err)
+	}
+}
+
+// diagnosticRecorder keeps the diagnostics it receives.
+type diagnosticRecorder struct {
+	diagnostics []Diagnostic
+}
+
+func (recorder *diagnosticRecorder) LogDiagnostic(diagnostic Diagnostic) {
+	recorder.diagnostics = append(recorder.diagnostics, diagnostic)
+}
+
+func TestAnalysisCacheHitLogsDiagnostics(t *testing.T) {
+	folderPath := t.TempDir()
+	demoPath := filepath.Join(folderPath, "match.dem")
+	if err := os.WriteFile(demoPath, buildSource1Demo(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the header of the demo is valid, the export succeeds only if the match is read from the cache.
+	infos, err := InspectDemos(demoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := newAnalysisCache(t.TempDir(), AnalyzeDemoOptions{})
+	key, err := cache.key(infos[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	match := newMatchWithOneOfEachEvent()
+	match.Checksum = infos[0].Checksum
+	match.Winner = match.TeamA
+	match.Diagnostics = []*Diagnostic{{Tick: 10, RoundNumber: 1, Code: constants.DiagnosticCodeUnknownGameMode}}
+	if err = cache.write(key, match); err != nil {
+		t.Fatal(err)
+	}
+
+	var outputs [][]byte
+	for range 2 {
+		recorder := &diagnosticRecorder{}
+		outputFolderPath := t.TempDir()
+		err = AnalyzeAndExportDemo(demoPath, outputFolderPath, AnalyzeAndExportDemoOptions{
+			Format:          constants.ExportFormatJSON,
+			Logger:          recorder,
+			CacheFolderPath: cache.folderPath,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(recorder.diagnostics) != 1 || recorder.diagnostics[0] != *match.Diagnostics[0] {
+			t.Errorf("expected the cached diagnostic to be logged, got %v", recorder.diagnostics)
+		}
+
+		output, err := os.ReadFile(filepath.Join(outputFolderPath, "match.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs = append(outputs, output)
+	}
+
+	if !bytes.Equal(outputs[0], outputs[1]) {
+		t.Error("the exports of the same cached match differ")
+	}
+}
@@ -0,0 +1,18 @@
+package constants
+
+type Compression string
+
+func (compression Compression) String() string {
+	return string(compression)
+}
+
+const (
+	CompressionNone Compression = ""
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+var Compressions = []Compression{
+	CompressionGzip,
+	CompressionZstd,
+}
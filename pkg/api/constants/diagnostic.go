@@ -0,0 +1,32 @@
+package constants
+
+type DiagnosticSeverity string
+
+func (severity DiagnosticSeverity) String() string {
+	return string(severity)
+}
+
+const (
+	DiagnosticSeverityInfo    DiagnosticSeverity = "info"
+	DiagnosticSeverityWarning DiagnosticSeverity = "warning"
+	DiagnosticSeverityError   DiagnosticSeverity = "error"
+)
+
+type DiagnosticCode string
+
+func (code DiagnosticCode) String() string {
+	return string(code)
+}
+
+const (
+	DiagnosticCodeParserWarning       DiagnosticCode = "parser_warning"       // Warning emitted by the demo parser
+	DiagnosticCodeCorruptedDemo       DiagnosticCode = "corrupted_demo"       // The demo ended unexpectedly, data after this point are missing
+	DiagnosticCodeInvalidMatchInfo    DiagnosticCode = "invalid_match_info"   // The .info file of the demo can't be read
+	DiagnosticCodeUnknownGameMode     DiagnosticCode = "unknown_game_mode"    // The game mode is unknown, competitive is assumed
+	DiagnosticCodeMatchAborted        DiagnosticCode = "match_aborted"        // The match has been aborted, i.e. because of a VAC live ban
+	DiagnosticCodeMissingWeapon       DiagnosticCode = "missing_weapon"       // Event without weapon, it has been ignored
+	DiagnosticCodeMissingPlayer       DiagnosticCode = "missing_player"       // Event without a player (victim, killer...), it has been ignored
+	DiagnosticCodeMissingGrenade      DiagnosticCode = "missing_grenade"      // Grenade event without its grenade or projectile, it has been ignored
+	DiagnosticCodeMissingThrower      DiagnosticCode = "missing_thrower"      // Grenade event without thrower, the owner is used if possible
+	DiagnosticCodeUnmatchedProjectile DiagnosticCode = "unmatched_projectile" // Grenade projectile thrown without a weapon fire event
+)
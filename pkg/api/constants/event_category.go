@@ -0,0 +1,32 @@
+package constants
+
+// EventCategory is a group of events that can be included or excluded from the analysis.
+type EventCategory string
+
+const (
+	EventCategoryKills     EventCategory = "kills"
+	EventCategoryDamages   EventCategory = "damages"
+	EventCategoryShots     EventCategory = "shots"
+	EventCategoryGrenades  EventCategory = "grenades" // Grenades events and players flashed
+	EventCategoryBombs     EventCategory = "bombs"
+	EventCategoryPositions EventCategory = "positions" // Players positions, and grenades/chickens/hostages positions if their category is included
+	EventCategoryChickens  EventCategory = "chickens"
+	EventCategoryHostages  EventCategory = "hostages"
+	EventCategoryChat      EventCategory = "chat"
+	EventCategoryEconomy   EventCategory = "economy" // Players economies and buys
+	EventCategoryClutches  EventCategory = "clutches"
+)
+
+var EventCategories = []EventCategory{
+	EventCategoryKills,
+	EventCategoryDamages,
+	EventCategoryShots,
+	EventCategoryGrenades,
+	EventCategoryBombs,
+	EventCategoryPositions,
+	EventCategoryChickens,
+	EventCategoryHostages,
+	EventCategoryChat,
+	EventCategoryEconomy,
+	EventCategoryClutches,
+}
@@ -3,13 +3,23 @@ package constants
 type ExportFormat string
 
 const (
-	ExportFormatCSV  ExportFormat = "csv"
-	ExportFormatJSON ExportFormat = "json"
-	ExportFormatCSDM ExportFormat = "csdm" // Special CSV export dedicated to the application CS Demo Manager
+	ExportFormatCSV      ExportFormat = "csv"
+	ExportFormatJSON     ExportFormat = "json"
+	ExportFormatCSDM     ExportFormat = "csdm"     // Special CSV export dedicated to the application CS Demo Manager
+	ExportFormatNDJSON   ExportFormat = "ndjson"   // One JSON object per line, written while the demo is being analyzed
+	ExportFormatParquet  ExportFormat = "parquet"  // One typed Parquet file per entity
+	ExportFormatSQLite   ExportFormat = "sqlite"   // SQLite database that may contain several matches
+	ExportFormatPostgres ExportFormat = "postgres" // PostgreSQL schema, COPY files and load script
+	ExportFormatProtobuf ExportFormat = "protobuf" // Length-delimited protobuf records described by proto/csda/v1/match.proto
 )
 
 var ExportFormats = []ExportFormat{
 	ExportFormatCSV,
 	ExportFormatJSON,
 	ExportFormatCSDM,
+	ExportFormatNDJSON,
+	ExportFormatParquet,
+	ExportFormatSQLite,
+	ExportFormatPostgres,
+	ExportFormatProtobuf,
 }
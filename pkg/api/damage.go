@@ -1,8 +1,6 @@
 package api
 
 import (
-	"fmt"
-
 	"github.com/akiver/cs-demo-analyzer/internal/math"
 	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
 	"github.com/markus-wa/demoinfocs-golang/v4/pkg/demoinfocs/common"
@@ -59,7 +57,7 @@ func (damage *Damage) isValidPlayerDamageEvent(player *Player) bool {
 
 func newDamageFromGameEvent(analyzer *Analyzer, event events.PlayerHurt) *Damage {
 	if event.Weapon == nil {
-		fmt.Println("Player hurt event without weapon occurred")
+		analyzer.addDiagnostic(constants.DiagnosticSeverityWarning, constants.DiagnosticCodeMissingWeapon, "Player hurt event without weapon occurred")
 		return nil
 	}
 	parser := analyzer.parser
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+)
+
+// Diagnostic is a problem detected during the analysis that didn't prevent it from completing, i.e. an event without
+// player. A demo with a lot of diagnostics may be corrupted or recorded with an unusual setup.
+type Diagnostic struct {
+	Tick        int                          `json:"tick"`
+	RoundNumber int                          `json:"roundNumber"`
+	Severity    constants.DiagnosticSeverity `json:"severity"`
+	Code        constants.DiagnosticCode     `json:"code"`
+	Message     string                       `json:"message"`
+}
+
+// DiagnosticLogger receives the diagnostics as soon as they are detected, see AnalyzeDemoOptions.Logger.
+type DiagnosticLogger interface {
+	LogDiagnostic(diagnostic Diagnostic)
+}
+
+// addDiagnostic adds a diagnostic to the match, diagnostics are kept when the match restarts.
+func (analyzer *Analyzer) addDiagnostic(severity constants.DiagnosticSeverity, code constants.DiagnosticCode, message string) {
+	diagnostic := &Diagnostic{
+		Tick:        analyzer.currentTick(),
+		RoundNumber: analyzer.currentRound.Number,
+		Severity:    severity,
+		Code:        code,
+		Message:     message,
+	}
+	analyzer.match.Diagnostics = append(analyzer.match.Diagnostics, diagnostic)
+
+	if analyzer.logger != nil {
+		analyzer.logger.LogDiagnostic(*diagnostic)
+	}
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+)
+
+// ErrUnknownSource is returned when the source of the demo can't be detected, it must be provided with the Source
+// option.
+var ErrUnknownSource = errors.New("unknown demo source, please specify the source with the -source flag (UnknownSource)")
+
+// ErrMissingGameEventDescriptors is returned when a CS2 demo doesn't contain the game events list, it happens with demos
+// recorded on servers with sv_hibernate_when_empty 0.
+var ErrMissingGameEventDescriptors = errors.New("missing game event descriptors (ErrMissingGameEventDescriptors)")
+
+// UnsupportedDemoError is returned when demos from a source are not supported.
+// Game is set when only demos of this game are not supported for the source, Type is set when only demos of this type
+// are not supported.
+type UnsupportedDemoError struct {
+	Source constants.DemoSource
+	Game   constants.Game
+	Type   constants.DemoType
+}
+
+// Code returns the code of the error, i.e. "CevoNotSupported".
+func (err *UnsupportedDemoError) Code() string {
+	switch {
+	case err.Type == constants.DemoTypePOV:
+		return "CS2POVDemosNotSupported"
+	case err.Source == constants.DemoSourceCEVO:
+		return "CevoNotSupported"
+	case err.Source == constants.DemoSourceGamersclub:
+		return "GamersClubNotSupported"
+	case err.Source == constants.DemoSourcePopFlash:
+		return "PopFlashNotSupported"
+	}
+
+	return "DemoNotSupported"
+}
+
+func (err *UnsupportedDemoError) Error() string {
+	switch err.Code() {
+	case "CS2POVDemosNotSupported":
+		return "cs2 pov demos are not supported (CS2POVDemosNotSupported)"
+	case "PopFlashNotSupported":
+		return "cs2 PopFlash demos are not supported (PopFlashNotSupported)"
+	}
+
+	if err.Game != "" {
+		return fmt.Sprintf("%s %s demos are not supported (%s)", err.Game, err.Source, err.Code())
+	}
+
+	return fmt.Sprintf("%s demos are not supported (%s)", err.Source, err.Code())
+}
+
+// CancelledError is returned when the analysis has been stopped because its context is done, i.e. the context has been
+// cancelled or its deadline exceeded.
+// Err is the context error, use errors.Is(err, context.DeadlineExceeded) to detect timeouts.
+type CancelledError struct {
+	Err error
+	// Match built until the analysis was cancelled, it's post-processed the same way as a corrupted demo is.
+	// It's nil if the analysis was cancelled before the parsing started.
+	// The checksum of compressed demos is empty because it requires reading the whole demo.
+	Match *Match
+}
+
+func (err *CancelledError) Error() string {
+	return fmt.Sprintf("analysis cancelled: %v (Cancelled)", err.Err)
+}
+
+func (err *CancelledError) Unwrap() error {
+	return err.Err
+}
+
+// ErrorCode returns the code of an error returned by the analysis, i.e. "UnknownSource" or "CevoNotSupported".
+// It returns "Error" if the error is not one of the errors of this package.
+func ErrorCode(err error) string {
+	var unsupportedDemoErr *UnsupportedDemoError
+	var cancelledErr *CancelledError
+	switch {
+	case errors.Is(err, ErrUnknownSource):
+		return "UnknownSource"
+	case errors.Is(err, ErrMissingGameEventDescriptors):
+		return "MissingGameEventDescriptors"
+	case errors.As(err, &unsupportedDemoErr):
+		return unsupportedDemoErr.Code()
+	case errors.As(err, &cancelledErr):
+		return "Cancelled"
+	}
+
+	return "Error"
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/akiver/cs-demo-analyzer/internal/slice"
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+)
+
+func FormatValidEventCategories() string {
+	var categories []string
+	for _, category := range constants.EventCategories {
+		categories = append(categories, string(category))
+	}
+
+	return "[" + strings.Join(categories, ",") + "]"
+}
+
+func ValidateEventCategory(category constants.EventCategory) error {
+	isValid := slice.Contains(constants.EventCategories, category)
+	if isValid {
+		return nil
+	}
+
+	return fmt.Errorf("invalid event category %q provided, valid categories: %s", category, FormatValidEventCategories())
+}
+
+// resolveEventCategories returns the categories of events that will be collected.
+// All categories except positions are collected by default, positions are collected if includePositions is true or if
+// they are explicitly included. When include is not empty, only the given categories are collected.
+func resolveEventCategories(include []constants.EventCategory, exclude []constants.EventCategory, includePositions bool) (map[constants.EventCategory]bool, error) {
+	for _, category := range slices.Concat(include, exclude) {
+		if err := ValidateEventCategory(category); err != nil {
+			return nil, err
+		}
+	}
+
+	categories := make(map[constants.EventCategory]bool)
+	if len(include) == 0 {
+		for _, category := range constants.EventCategories {
+			categories[category] = category != constants.EventCategoryPositions
+		}
+	}
+
+	for _, category := range include {
+		categories[category] = true
+	}
+
+	if includePositions {
+		categories[constants.EventCategoryPositions] = true
+	}
+
+	for _, category := range exclude {
+		categories[category] = false
+	}
+
+	return categories, nil
+}
+
+// collects returns true if the events of the given category are collected.
+// Events used to compute the players and rounds stats (kills, damages, shots, clutches, buys, economies, bombs
+// planted/defused/exploded and hostages rescued) are always kept in the match, even with the DiscardEvents option, for
+// them it returns true if they are sent to the EventSink and exported.
+func (analyzer *Analyzer) collects(category constants.EventCategory) bool {
+	return analyzer.eventCategories[category]
+}
+
+// isEventCategoryExported returns true if the export files of the given category should be written.
+// Files are always written when no categories have been included/excluded explicitly, even if they are empty.
+func (match *Match) isEventCategoryExported(category constants.EventCategory) bool {
+	return match.eventCategories == nil || match.eventCategories[category]
+}
+
+// areEventCategoriesExported returns true if all the categories are exported, files that depend on several categories,
+// i.e. grenade positions, are written only if all of them are exported.
+func (match *Match) areEventCategoriesExported(categories []constants.EventCategory) bool {
+	return !slices.ContainsFunc(categories, func(category constants.EventCategory) bool {
+		return !match.isEventCategoryExported(category)
+	})
+}
+
+// withoutExcludedEvents returns a copy of the match without the events that are always collected to compute stats but
+// whose category has not been included. Players and rounds still compute their stats from the original match.
+func (match *Match) withoutExcludedEvents() *Match {
+	if match.eventCategories == nil {
+		return match
+	}
+
+	exportedMatch := *match
+	if !match.isEventCategoryExported(constants.EventCategoryKills) {
+		exportedMatch.Kills = []*Kill{}
+	}
+	if !match.isEventCategoryExported(constants.EventCategoryDamages) {
+		exportedMatch.Damages = []*Damage{}
+	}
+	if !match.isEventCategoryExported(constants.EventCategoryShots) {
+		exportedMatch.Shots = []*Shot{}
+	}
+	if !match.isEventCategoryExported(constants.EventCategoryClutches) {
+		exportedMatch.Clutches = []*Clutch{}
+	}
+	if !match.isEventCategoryExported(constants.EventCategoryEconomy) {
+		exportedMatch.PlayerEconomies = []*PlayerEconomy{}
+		exportedMatch.PlayersBuy = []*PlayerBuy{}
+	}
+	if !match.isEventCategoryExported(constants.EventCategoryBombs) {
+		exportedMatch.BombsPlanted = []*BombPlanted{}
+		exportedMatch.BombsDefused = []*BombDefused{}
+		exportedMatch.BombsExploded = []*BombExploded{}
+	}
+	if !match.isEventCategoryExported(constants.EventCategoryHostages) {
+		exportedMatch.HostageRescued = []*HostageRescued{}
+	}
+
+	return &exportedMatch
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+)
+
+func TestExcludedEventsKeepPlayersStats(t *testing.T) {
+	match := newSeriesTestMatch(time.Now(), "A", "B", constants.TeamLetterA, 1, 2)
+	match.Rounds[0].analyzer = &Analyzer{match: match}
+	match.Rounds[1].analyzer = match.Rounds[0].analyzer
+	expectedJSON, err := json.Marshal(match.PlayersBySteamID[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	match.eventCategories, err = resolveEventCategories(nil, []constants.EventCategory{constants.EventCategoryKills, constants.EventCategoryDamages}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var exportedMatch struct {
+		Players map[string]json.RawMessage `json:"players"`
+		Kills   []*Kill                    `json:"kills"`
+		Damages []*Damage                  `json:"damages"`
+	}
+	matchJSON, err := json.Marshal(match)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(matchJSON, &exportedMatch); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(exportedMatch.Kills) != 0 || len(exportedMatch.Damages) != 0 {
+		t.Errorf("excluded events exported: %d kills, %d damages", len(exportedMatch.Kills), len(exportedMatch.Damages))
+	}
+	if string(exportedMatch.Players["1"]) != string(expectedJSON) {
+		t.Errorf("player stats changed when kills are excluded\nexpected: %s\ngot:      %s", expectedJSON, exportedMatch.Players["1"])
+	}
+	if len(match.Kills) != 1 {
+		t.Error("kills removed from the match")
+	}
+
+	tableNames := make([]string, 0)
+	for _, table := range buildExportTables(match) {
+		tableNames = append(tableNames, table.name)
+	}
+	if slices.Contains(tableNames, "kills") || slices.Contains(tableNames, "damages") {
+		t.Errorf("tables of excluded events exported: %v", tableNames)
+	}
+}
+
+// shotCountingSink counts the shots sent to the sink.
+type shotCountingSink struct {
+	NopEventSink
+	shotCount int
+}
+
+func (sink *shotCountingSink) OnShot(shot *Shot) {
+	sink.shotCount++
+}
+
+func TestDiscardEventsKeepsShots(t *testing.T) {
+	for _, discardEvents := range []bool{false, true} {
+		match := newMatchWithOneOfEachEvent()
+		match.Shots = nil
+		sink := &shotCountingSink{}
+		eventCategories, err := resolveEventCategories(nil, nil, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		analyzer := &Analyzer{
+			match:           match,
+			sink:            sink,
+			discardEvents:   discardEvents,
+			eventCategories: eventCategories,
+		}
+
+		analyzer.addShot(&Shot{RoundNumber: 1})
+		analyzer.addShot(&Shot{RoundNumber: 1})
+
+		if match.ShotCount() != 2 {
+			t.Errorf("discard events %v: expected a shot count of 2, got %d", discardEvents, match.ShotCount())
+		}
+		if sink.shotCount != 2 {
+			t.Errorf("discard events %v: expected 2 shots sent to the sink, got %d", discardEvents, sink.shotCount)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"github.com/akiver/cs-demo-analyzer/internal/slice"
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+)
+
+// EventSink receives the objects built by the analyzer as soon as they are created, i.e. to insert them into a database
+// while the demo is being analyzed. Embed NopEventSink to implement only the methods you need.
+//
+// A few values are updated after an object has been sent, such as Kill.IsTradeDeath, Shot.ProjectileID or
+// PlayerBuy.HasRefunded. Since the objects are pointers, values are final once OnRoundEnd is called for their round.
+// Objects of a round are part of the match only if OnRoundEnd has been called for it, incomplete rounds are removed at the
+// end of the analysis. When the match restarts, OnMatchReset is called and previous objects must be dropped.
+// When a round is restarted, i.e. after a backup restoration, OnRoundReset is called and the objects of that round must
+// be dropped, they are sent again when the round is replayed.
+//
+// Player economies and clutches are sent when their round ends because their values are computed during the round.
+// Methods are called from the parsing goroutine, a slow sink slows down the analysis.
+type EventSink interface {
+	OnKill(kill *Kill)
+	OnDamage(damage *Damage)
+	OnShot(shot *Shot)
+	OnClutch(clutch *Clutch)
+	OnPlayerBuy(buy *PlayerBuy)
+	OnPlayerEconomy(economy *PlayerEconomy)
+	OnPlayerFlashed(playerFlashed *PlayerFlashed)
+	OnBombPlantStart(bombPlantStart *BombPlantStart)
+	OnBombPlanted(bombPlanted *BombPlanted)
+	OnBombDefuseStart(bombDefuseStart *BombDefuseStart)
+	OnBombDefused(bombDefused *BombDefused)
+	OnBombExploded(bombExploded *BombExploded)
+	OnHeGrenadeExplode(heGrenadeExplode *HeGrenadeExplode)
+	OnFlashbangExplode(flashbangExplode *FlashbangExplode)
+	OnSmokeStart(smokeStart *SmokeStart)
+	OnDecoyStart(decoyStart *DecoyStart)
+	OnGrenadeBounce(grenadeBounce *GrenadeBounce)
+	OnGrenadeProjectileDestroy(grenadeProjectileDestroy *GrenadeProjectileDestroy)
+	OnHostagePickUpStart(hostagePickUpStart *HostagePickUpStart)
+	OnHostagePickedUp(hostagePickedUp *HostagePickedUp)
+	OnHostageRescued(hostageRescued *HostageRescued)
+	OnChickenDeath(chickenDeath *ChickenDeath)
+	OnChatMessage(chatMessage *ChatMessage)
+	OnPlayerPosition(position *PlayerPosition)
+	OnGrenadePosition(position *GrenadePosition)
+	OnInfernoPosition(position *InfernoPosition)
+	OnHostagePosition(position *HostagePosition)
+	OnChickenPosition(position *ChickenPosition)
+	OnRoundEnd(round *Round)
+	OnMatchReset()
+	OnRoundReset(roundNumber int)
+}
+
+// NopEventSink is an EventSink that does nothing.
+type NopEventSink struct{}
+
+func (NopEventSink) OnKill(kill *Kill)                                                             {}
+func (NopEventSink) OnDamage(damage *Damage)                                                       {}
+func (NopEventSink) OnShot(shot *Shot)                                                             {}
+func (NopEventSink) OnClutch(clutch *Clutch)                                                       {}
+func (NopEventSink) OnPlayerBuy(buy *PlayerBuy)                                                    {}
+func (NopEventSink) OnPlayerEconomy(economy *PlayerEconomy)                                        {}
+func (NopEventSink) OnPlayerFlashed(playerFlashed *PlayerFlashed)                                  {}
+func (NopEventSink) OnBombPlantStart(bombPlantStart *BombPlantStart)                               {}
+func (NopEventSink) OnBombPlanted(bombPlanted *BombPlanted)                                        {}
+func (NopEventSink) OnBombDefuseStart(bombDefuseStart *BombDefuseStart)                            {}
+func (NopEventSink) OnBombDefused(bombDefused *BombDefused)                                        {}
+func (NopEventSink) OnBombExploded(bombExploded *BombExploded)                                     {}
+func (NopEventSink) OnHeGrenadeExplode(heGrenadeExplode *HeGrenadeExplode)                         {}
+func (NopEventSink) OnFlashbangExplode(flashbangExplode *FlashbangExplode)                         {}
+func (NopEventSink) OnSmokeStart(smokeStart *SmokeStart)                                           {}
+func (NopEventSink) OnDecoyStart(decoyStart *DecoyStart)                                           {}
+func (NopEventSink) OnGrenadeBounce(grenadeBounce *GrenadeBounce)                                  {}
+func (NopEventSink) OnGrenadeProjectileDestroy(grenadeProjectileDestroy *GrenadeProjectileDestroy) {}
+func (NopEventSink) OnHostagePickUpStart(hostagePickUpStart *HostagePickUpStart)                   {}
+func (NopEventSink) OnHostagePickedUp(hostagePickedUp *HostagePickedUp)                            {}
+func (NopEventSink) OnHostageRescued(hostageRescued *HostageRescued)                               {}
+func (NopEventSink) OnChickenDeath(chickenDeath *ChickenDeath)                                     {}
+func (NopEventSink) OnChatMessage(chatMessage *ChatMessage)                                        {}
+func (NopEventSink) OnPlayerPosition(position *PlayerPosition)                                     {}
+func (NopEventSink) OnGrenadePosition(position *GrenadePosition)                                   {}
+func (NopEventSink) OnInfernoPosition(position *InfernoPosition)                                   {}
+func (NopEventSink) OnHostagePosition(position *HostagePosition)                                   {}
+func (NopEventSink) OnChickenPosition(position *ChickenPosition)                                   {}
+func (NopEventSink) OnRoundEnd(round *Round)                                                       {}
+func (NopEventSink) OnMatchReset()                                                                 {}
+func (NopEventSink) OnRoundReset(roundNumber int)                                                  {}
+
+// addEvent sends the event to the sink and keeps it in the match unless the DiscardEvents option is set.
+// Events required during the analysis or to compute players stats (kills, damages, shots, clutches, buys, economies,
+// bombs planted/defused/exploded and hostages rescued) are always kept and must not be added with this function.
+func addEvent[T any](analyzer *Analyzer, events *[]T, event T, sinkEvent func(event T)) {
+	sinkEvent(event)
+	if !analyzer.discardEvents {
+		*events = append(*events, event)
+	}
+}
+
+// sendRoundEnd sends the round and the objects that were completed at the end of it to the sink.
+// Rounds that are not part of the match because they are incomplete are ignored.
+func (analyzer *Analyzer) sendRoundEnd(round *Round) {
+	match := analyzer.match
+	if round.WinnerName == "" || !slice.Contains(match.Rounds, round) {
+		return
+	}
+
+	for _, economy := range match.PlayerEconomies {
+		if economy.RoundNumber == round.Number && analyzer.collects(constants.EventCategoryEconomy) {
+			analyzer.sink.OnPlayerEconomy(economy)
+		}
+	}
+
+	for _, clutch := range match.Clutches {
+		if clutch.RoundNumber == round.Number && analyzer.collects(constants.EventCategoryClutches) {
+			analyzer.sink.OnClutch(clutch)
+		}
+	}
+
+	analyzer.sink.OnRoundEnd(round)
+}
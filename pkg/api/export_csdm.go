@@ -7,26 +7,18 @@
 package api
 
 import (
-	"errors"
 	"fmt"
-	"os"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/akiver/cs-demo-analyzer/internal/converters"
-	"github.com/akiver/cs-demo-analyzer/internal/csv"
 	"github.com/akiver/cs-demo-analyzer/internal/slice"
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
 	"github.com/markus-wa/demoinfocs-golang/v4/pkg/demoinfocs/common"
 )
 
-func exportMatchForCSDM(match *Match, outputPath string) error {
-	if stat, err := os.Stat(outputPath); err != nil || !stat.IsDir() {
-		return errors.New("incorrect output provided, make sure it's a folder that exists and you have write access")
-	}
-
-	outputPath = outputPath + string(os.PathSeparator) + match.DemoFileName
-
+func exportMatchForCSDM(match *Match, output *exportOutput) error {
 	var writeDemo = func() {
 		line := []string{
 			match.Checksum,
@@ -47,7 +39,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			converters.IntToString(match.BuildNumber),
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_demo.csv", [][]string{
+		output.writeCSVFile(match.DemoFileName+"_demo.csv", [][]string{
 			line,
 		})
 	}
@@ -77,7 +69,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			converters.BoolToString(match.HasVacLiveBan),
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_match.csv", [][]string{
+		output.writeCSVFile(match.DemoFileName+"_match.csv", [][]string{
 			line,
 		})
 	}
@@ -97,7 +89,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 	}
 
 	var writeTeams = func() {
-		csv.WriteLinesIntoCsvFile(outputPath+"_teams.csv", [][]string{
+		output.writeCSVFile(match.DemoFileName+"_teams.csv", [][]string{
 			buildTeamLine(match.TeamA),
 			buildTeamLine(match.TeamB),
 		})
@@ -153,7 +145,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_players.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_players.csv", lines)
 	}
 
 	var writePlayerPositions = func() {
@@ -196,7 +188,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_positions.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_positions.csv", lines)
 	}
 
 	var writeShots = func() {
@@ -232,7 +224,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_shots.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_shots.csv", lines)
 	}
 
 	var writeRounds = func() {
@@ -272,7 +264,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_rounds.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_rounds.csv", lines)
 	}
 
 	var writeRoundEconomies = func() {
@@ -292,7 +284,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_players_economy.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_players_economy.csv", lines)
 	}
 
 	var writeClutches = func() {
@@ -314,7 +306,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_clutches.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_clutches.csv", lines)
 	}
 
 	var writeChickenDeaths = func() {
@@ -331,7 +323,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_chicken_deaths.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_chicken_deaths.csv", lines)
 	}
 
 	var writeChickenPositions = func() {
@@ -349,7 +341,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_chicken_positions.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_chicken_positions.csv", lines)
 	}
 
 	var writeDamages = func() {
@@ -382,7 +374,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_damages.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_damages.csv", lines)
 	}
 
 	var writeKills = func() {
@@ -436,7 +428,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_kills.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_kills.csv", lines)
 	}
 
 	var writeBombsPlanted = func() {
@@ -458,7 +450,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_bombs_planted.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_bombs_planted.csv", lines)
 	}
 
 	var writeBombsDefuseStart = func() {
@@ -479,7 +471,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_bombs_defuse_start.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_bombs_defuse_start.csv", lines)
 	}
 
 	var writeBombsDefused = func() {
@@ -503,7 +495,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_bombs_defused.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_bombs_defused.csv", lines)
 	}
 
 	var writeBombsExploded = func() {
@@ -525,7 +517,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_bombs_exploded.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_bombs_exploded.csv", lines)
 	}
 
 	var writeBombsPlantStart = func() {
@@ -547,7 +539,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_bombs_plant_start.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_bombs_plant_start.csv", lines)
 	}
 
 	var writePlayersFlashed = func() {
@@ -571,7 +563,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_players_flashed.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_players_flashed.csv", lines)
 	}
 
 	var writePlayersBuy = func() {
@@ -593,7 +585,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_players_buy.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_players_buy.csv", lines)
 	}
 
 	var writeGrenadePositions = func() {
@@ -623,7 +615,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_grenade_positions.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_grenade_positions.csv", lines)
 	}
 
 	var writeGrenadeBounces = func() {
@@ -653,7 +645,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_grenade_bounces.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_grenade_bounces.csv", lines)
 	}
 
 	var writeGrenadeProjectilesDestroy = func() {
@@ -683,7 +675,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_grenade_projectiles_destroy.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_grenade_projectiles_destroy.csv", lines)
 	}
 
 	var writeSmokesStart = func() {
@@ -712,7 +704,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_smokes_start.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_smokes_start.csv", lines)
 	}
 
 	var writeHeGrenadesExplode = func() {
@@ -741,7 +733,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_he_grenades_explode.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_he_grenades_explode.csv", lines)
 	}
 
 	var writeFlashbangsExplode = func() {
@@ -770,7 +762,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_flashbangs_explode.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_flashbangs_explode.csv", lines)
 	}
 
 	var writeDecoysStart = func() {
@@ -799,7 +791,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_decoys_start.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_decoys_start.csv", lines)
 	}
 
 	var writeInfernoPositions = func() {
@@ -832,7 +824,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_inferno_positions.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_inferno_positions.csv", lines)
 	}
 
 	var writeChatMessages = func() {
@@ -852,7 +844,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_chat_messages.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_chat_messages.csv", lines)
 	}
 
 	var writeHostagePositions = func() {
@@ -871,7 +863,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_hostage_positions.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_hostage_positions.csv", lines)
 	}
 
 	var writeHostagePickUpStart = func() {
@@ -892,7 +884,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_hostage_pick_up_start.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_hostage_pick_up_start.csv", lines)
 	}
 
 	var writeHostagePickedUp = func() {
@@ -913,7 +905,7 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_hostage_picked_up.csv", lines)
+		output.writeCSVFile(match.DemoFileName+"_hostage_picked_up.csv", lines)
 	}
 
 	var writeHostageRescued = func() {
@@ -934,56 +926,82 @@ func exportMatchForCSDM(match *Match, outputPath string) error {
 			lines = append(lines, line)
 		}
 
-		csv.WriteLinesIntoCsvFile(outputPath+"_hostage_rescued.csv", lines)
-	}
-
-	var functions = []func(){
-		writeMatch,
-		writeDemo,
-		writeTeams,
-		writePlayers,
-		writePlayerPositions,
-		writeShots,
-		writeRounds,
-		writeRoundEconomies,
-		writeClutches,
-		writeChickenDeaths,
-		writeChickenPositions,
-		writeDamages,
-		writeKills,
-		writeBombsPlanted,
-		writeBombsDefuseStart,
-		writeBombsDefused,
-		writeBombsExploded,
-		writeBombsPlantStart,
-		writePlayersFlashed,
-		writePlayersBuy,
-		writeGrenadePositions,
-		writeGrenadeBounces,
-		writeGrenadeProjectilesDestroy,
-		writeSmokesStart,
-		writeHeGrenadesExplode,
-		writeFlashbangsExplode,
-		writeDecoysStart,
-		writeInfernoPositions,
-		writeChatMessages,
-		writeHostagePositions,
-		writeHostagePickUpStart,
-		writeHostagePickedUp,
-		writeHostageRescued,
+		output.writeCSVFile(match.DemoFileName+"_hostage_rescued.csv", lines)
+	}
+
+	var writeDiagnostics = func() {
+		lines := [][]string{}
+		for _, diagnostic := range match.Diagnostics {
+			line := []string{
+				converters.IntToString(diagnostic.Tick),
+				converters.IntToString(diagnostic.RoundNumber),
+				diagnostic.Severity.String(),
+				diagnostic.Code.String(),
+				diagnostic.Message,
+				match.Checksum,
+			}
+			lines = append(lines, line)
+		}
+
+		output.writeCSVFile(match.DemoFileName+"_diagnostics.csv", lines)
+	}
+
+	// Files of events categories that have not been collected are not written, like with the CSV export.
+	var writers = []struct {
+		categories []constants.EventCategory
+		write      func()
+	}{
+		{nil, writeMatch},
+		{nil, writeDemo},
+		{nil, writeTeams},
+		{nil, writePlayers},
+		{[]constants.EventCategory{constants.EventCategoryPositions}, writePlayerPositions},
+		{[]constants.EventCategory{constants.EventCategoryShots}, writeShots},
+		{nil, writeRounds},
+		{[]constants.EventCategory{constants.EventCategoryEconomy}, writeRoundEconomies},
+		{[]constants.EventCategory{constants.EventCategoryClutches}, writeClutches},
+		{[]constants.EventCategory{constants.EventCategoryChickens}, writeChickenDeaths},
+		{[]constants.EventCategory{constants.EventCategoryPositions, constants.EventCategoryChickens}, writeChickenPositions},
+		{[]constants.EventCategory{constants.EventCategoryDamages}, writeDamages},
+		{[]constants.EventCategory{constants.EventCategoryKills}, writeKills},
+		{[]constants.EventCategory{constants.EventCategoryBombs}, writeBombsPlanted},
+		{[]constants.EventCategory{constants.EventCategoryBombs}, writeBombsDefuseStart},
+		{[]constants.EventCategory{constants.EventCategoryBombs}, writeBombsDefused},
+		{[]constants.EventCategory{constants.EventCategoryBombs}, writeBombsExploded},
+		{[]constants.EventCategory{constants.EventCategoryBombs}, writeBombsPlantStart},
+		{[]constants.EventCategory{constants.EventCategoryGrenades}, writePlayersFlashed},
+		{[]constants.EventCategory{constants.EventCategoryEconomy}, writePlayersBuy},
+		{[]constants.EventCategory{constants.EventCategoryPositions, constants.EventCategoryGrenades}, writeGrenadePositions},
+		{[]constants.EventCategory{constants.EventCategoryGrenades}, writeGrenadeBounces},
+		{[]constants.EventCategory{constants.EventCategoryGrenades}, writeGrenadeProjectilesDestroy},
+		{[]constants.EventCategory{constants.EventCategoryGrenades}, writeSmokesStart},
+		{[]constants.EventCategory{constants.EventCategoryGrenades}, writeHeGrenadesExplode},
+		{[]constants.EventCategory{constants.EventCategoryGrenades}, writeFlashbangsExplode},
+		{[]constants.EventCategory{constants.EventCategoryGrenades}, writeDecoysStart},
+		{[]constants.EventCategory{constants.EventCategoryPositions, constants.EventCategoryGrenades}, writeInfernoPositions},
+		{[]constants.EventCategory{constants.EventCategoryChat}, writeChatMessages},
+		{[]constants.EventCategory{constants.EventCategoryPositions, constants.EventCategoryHostages}, writeHostagePositions},
+		{[]constants.EventCategory{constants.EventCategoryHostages}, writeHostagePickUpStart},
+		{[]constants.EventCategory{constants.EventCategoryHostages}, writeHostagePickedUp},
+		{[]constants.EventCategory{constants.EventCategoryHostages}, writeHostageRescued},
+		{nil, writeDiagnostics},
 	}
 
 	var wg sync.WaitGroup
 
-	for _, function := range functions {
+	for _, writer := range writers {
+		if !match.areEventCategoriesExported(writer.categories) {
+			continue
+		}
+
 		wg.Add(1)
 		go func(function func()) {
 			defer wg.Done()
 			function()
-		}(function)
+		}(writer.write)
 	}
 
 	wg.Wait()
 
-	return nil
+	return output.err()
 }
@@ -25,3 +25,29 @@ func ValidateExportFormat(format constants.ExportFormat) error {
 
 	return fmt.Errorf("invalid format provided, valid formats: %s", FormatValidExportFormats())
 }
+
+func FormatValidCompressions() string {
+	var compressions []string
+	for _, compression := range constants.Compressions {
+		compressions = append(compressions, string(compression))
+	}
+
+	return "[" + strings.Join(compressions, ",") + "]"
+}
+
+// ValidateCompression returns an error if the compression is not supported by the export format.
+func ValidateCompression(compression constants.Compression, format constants.ExportFormat) error {
+	if compression == constants.CompressionNone {
+		return nil
+	}
+
+	if !slice.Contains(constants.Compressions, compression) {
+		return fmt.Errorf("invalid compression provided, valid compressions: %s", FormatValidCompressions())
+	}
+
+	if format != constants.ExportFormatCSV && format != constants.ExportFormatJSON && format != constants.ExportFormatCSDM {
+		return fmt.Errorf("compression is not supported by the %s format, supported formats: [csv,json,csdm]", format)
+	}
+
+	return nil
+}
@@ -4,11 +4,13 @@ import (
 	"encoding/json"
 	"errors"
 	"os"
+
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
 )
 
-func buildOutputFilePath(match *Match, outputPath string) (string, error) {
+func buildOutputFilePath(match *Match, outputPath string, extension string) (string, error) {
 	if outputPath == "" {
-		return match.DemoFilePath + ".json", nil
+		return match.DemoFilePath + extension, nil
 	}
 
 	stat, err := os.Stat(outputPath)
@@ -17,31 +19,28 @@ func buildOutputFilePath(match *Match, outputPath string) (string, error) {
 	}
 
 	if stat.IsDir() {
-		return outputPath + string(os.PathSeparator) + match.DemoFileName + ".json", nil
+		return outputPath + string(os.PathSeparator) + match.DemoFileName + extension, nil
 	}
 
 	return outputPath, nil
 }
 
-func exportMatchToJSON(match *Match, outputPath string, minify bool) error {
-	var err error
-	outputFilePath, err := buildOutputFilePath(match, outputPath)
+func exportMatchToJSON(match *Match, outputPath string, minify bool, fileCompression constants.Compression) error {
+	file, err := createOutputFile(match, outputPath, ".json", fileCompression)
 	if err != nil {
 		return err
 	}
+	defer file.Close()
 
-	var jsonString []byte
-	if minify {
-		jsonString, err = json.Marshal(match)
-	} else {
-		jsonString, err = json.MarshalIndent(match, "", "  ")
+	encoder := json.NewEncoder(file)
+	if !minify {
+		encoder.SetIndent("", "  ")
 	}
 
+	err = encoder.Encode(match)
 	if err != nil {
 		return err
 	}
 
-	err = os.WriteFile(outputFilePath, jsonString, os.ModePerm)
-
-	return err
+	return file.Close()
 }
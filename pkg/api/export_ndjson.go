@@ -0,0 +1,337 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/akiver/cs-demo-analyzer/internal/slice"
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+)
+
+// ndjsonLine is a line of the NDJSON export, Type is the kind of entity contained in Data.
+type ndjsonLine struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// ndjsonMatchHeader is the first line of the NDJSON export, it contains the match metadata.
+type ndjsonMatchHeader struct {
+	Checksum        string                `json:"checksum"`
+	Game            constants.Game        `json:"game"`
+	DemoFilePath    string                `json:"demoFilePath"`
+	DemoFileName    string                `json:"demoFileName"`
+	Source          constants.DemoSource  `json:"source"`
+	Type            constants.DemoType    `json:"type"`
+	MapName         string                `json:"mapName"`
+	ShareCode       string                `json:"shareCode"`
+	TickCount       int                   `json:"tickCount"`
+	TickRate        float64               `json:"tickrate"`
+	FrameRate       float64               `json:"framerate"`
+	Date            time.Time             `json:"date"`
+	Duration        time.Duration         `json:"duration"`
+	ServerName      string                `json:"serverName"`
+	ClientName      string                `json:"clientName"`
+	NetworkProtocol int                   `json:"networkProtocol"`
+	BuildNumber     int                   `json:"buildNumber"`
+	GameType        constants.GameType    `json:"gameType"`
+	GameMode        constants.GameMode    `json:"gameMode"`
+	GameModeStr     constants.GameModeStr `json:"gameModeStr"`
+	IsRanked        bool                  `json:"isRanked"`
+	MaxRounds       int                   `json:"maxRounds"`
+	OvertimeCount   int                   `json:"overtimeCount"`
+	HasVacLiveBan   bool                  `json:"hasVacLiveBan"`
+	TeamA           *Team                 `json:"teamA"`
+	TeamB           *Team                 `json:"teamB"`
+	Winner          *Team                 `json:"winner"`
+}
+
+type pendingNDJSONLine struct {
+	roundNumber int
+	line        ndjsonLine
+}
+
+// ndjsonExporter writes the NDJSON export while the demo is being analyzed.
+// It's an EventSink that keeps the objects of the current round in memory and writes them into a temporary file when
+// the round ends because some of their values are updated until then. Objects of incomplete rounds are dropped.
+// When the analysis is done, the export file is created with the match header, players and rounds lines followed by the
+// content of the temporary file.
+type ndjsonExporter struct {
+	NopEventSink
+	file         *os.File
+	writer       *bufio.Writer
+	encoder      *json.Encoder
+	pendingLines []pendingNDJSONLine
+	err          error // First error that occurred while writing the temporary file
+}
+
+func newNDJSONExporter() (*ndjsonExporter, error) {
+	file, err := os.CreateTemp("", "csda-*.ndjson")
+	if err != nil {
+		return nil, err
+	}
+
+	writer := bufio.NewWriter(file)
+
+	return &ndjsonExporter{
+		file:    file,
+		writer:  writer,
+		encoder: json.NewEncoder(writer),
+	}, nil
+}
+
+func (exporter *ndjsonExporter) add(entityType string, roundNumber int, data any) {
+	exporter.pendingLines = append(exporter.pendingLines, pendingNDJSONLine{
+		roundNumber: roundNumber,
+		line: ndjsonLine{
+			Type: entityType,
+			Data: data,
+		},
+	})
+}
+
+// reset clears the temporary file, it happens when the match restarts and once a match has been exported.
+func (exporter *ndjsonExporter) reset() {
+	exporter.pendingLines = nil
+	exporter.writer.Reset(exporter.file)
+	if _, err := exporter.file.Seek(0, io.SeekStart); err != nil && exporter.err == nil {
+		exporter.err = err
+	}
+	if err := exporter.file.Truncate(0); err != nil && exporter.err == nil {
+		exporter.err = err
+	}
+}
+
+func (exporter *ndjsonExporter) close() {
+	exporter.file.Close()
+	os.Remove(exporter.file.Name())
+}
+
+func (exporter *ndjsonExporter) export(match *Match, outputPath string) error {
+	defer exporter.reset()
+
+	if exporter.err != nil {
+		return exporter.err
+	}
+
+	err := exporter.writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	outputFile, err := createOutputFile(match, outputPath, ".ndjson", constants.CompressionNone)
+	if err != nil {
+		return err
+	}
+	defer outputFile.Close()
+
+	writer := bufio.NewWriter(outputFile)
+	encoder := json.NewEncoder(writer)
+	err = encoder.Encode(ndjsonLine{
+		Type: "match",
+		Data: ndjsonMatchHeader{
+			Checksum:        match.Checksum,
+			Game:            match.Game,
+			DemoFilePath:    match.DemoFilePath,
+			DemoFileName:    match.DemoFileName,
+			Source:          match.Source,
+			Type:            match.Type,
+			MapName:         match.MapName,
+			ShareCode:       match.ShareCode,
+			TickCount:       match.TickCount,
+			TickRate:        match.TickRate,
+			FrameRate:       match.FrameRate,
+			Date:            match.Date,
+			Duration:        match.Duration,
+			ServerName:      match.ServerName,
+			ClientName:      match.ClientName,
+			NetworkProtocol: match.NetworkProtocol,
+			BuildNumber:     match.BuildNumber,
+			GameType:        match.GameType,
+			GameMode:        match.GameMode,
+			GameModeStr:     match.GameModeStr(),
+			IsRanked:        match.IsRanked,
+			MaxRounds:       match.MaxRounds,
+			OvertimeCount:   match.OvertimeCount,
+			HasVacLiveBan:   match.HasVacLiveBan,
+			TeamA:           match.TeamA,
+			TeamB:           match.TeamB,
+			Winner:          match.Winner,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Players are sorted by SteamID like the keys of the JSON export so exports of the same demo are identical.
+	for _, steamID := range slices.Sorted(maps.Keys(match.PlayersBySteamID)) {
+		if err = encoder.Encode(ndjsonLine{Type: "player", Data: match.PlayersBySteamID[steamID]}); err != nil {
+			return err
+		}
+	}
+
+	for _, round := range match.Rounds {
+		if err = encoder.Encode(ndjsonLine{Type: "round", Data: round}); err != nil {
+			return err
+		}
+	}
+
+	for _, diagnostic := range match.Diagnostics {
+		if err = encoder.Encode(ndjsonLine{Type: "diagnostic", Data: diagnostic}); err != nil {
+			return err
+		}
+	}
+
+	if _, err = exporter.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(writer, exporter.file); err != nil {
+		return err
+	}
+
+	if err = writer.Flush(); err != nil {
+		return err
+	}
+
+	return outputFile.Close()
+}
+
+func (exporter *ndjsonExporter) OnRoundEnd(round *Round) {
+	for _, pendingLine := range exporter.pendingLines {
+		if pendingLine.roundNumber != round.Number || exporter.err != nil {
+			continue
+		}
+
+		exporter.err = exporter.encoder.Encode(pendingLine.line)
+	}
+
+	exporter.pendingLines = nil
+}
+
+// OnRoundReset drops the objects of the round, they are sent again when the round is replayed.
+func (exporter *ndjsonExporter) OnRoundReset(roundNumber int) {
+	exporter.pendingLines = slice.Filter(exporter.pendingLines, func(pendingLine pendingNDJSONLine, index int) bool {
+		return pendingLine.roundNumber != roundNumber
+	})
+}
+
+func (exporter *ndjsonExporter) OnMatchReset() {
+	exporter.reset()
+}
+
+func (exporter *ndjsonExporter) OnKill(kill *Kill) {
+	exporter.add("kill", kill.RoundNumber, kill)
+}
+
+func (exporter *ndjsonExporter) OnDamage(damage *Damage) {
+	exporter.add("damage", damage.RoundNumber, damage)
+}
+
+func (exporter *ndjsonExporter) OnShot(shot *Shot) {
+	exporter.add("shot", shot.RoundNumber, shot)
+}
+
+func (exporter *ndjsonExporter) OnClutch(clutch *Clutch) {
+	exporter.add("clutch", clutch.RoundNumber, clutch)
+}
+
+func (exporter *ndjsonExporter) OnPlayerBuy(buy *PlayerBuy) {
+	exporter.add("playerBuy", buy.RoundNumber, buy)
+}
+
+func (exporter *ndjsonExporter) OnPlayerEconomy(economy *PlayerEconomy) {
+	exporter.add("playerEconomy", economy.RoundNumber, economy)
+}
+
+func (exporter *ndjsonExporter) OnPlayerFlashed(playerFlashed *PlayerFlashed) {
+	exporter.add("playerFlashed", playerFlashed.RoundNumber, playerFlashed)
+}
+
+func (exporter *ndjsonExporter) OnBombPlantStart(bombPlantStart *BombPlantStart) {
+	exporter.add("bombPlantStart", bombPlantStart.RoundNumber, bombPlantStart)
+}
+
+func (exporter *ndjsonExporter) OnBombPlanted(bombPlanted *BombPlanted) {
+	exporter.add("bombPlanted", bombPlanted.RoundNumber, bombPlanted)
+}
+
+func (exporter *ndjsonExporter) OnBombDefuseStart(bombDefuseStart *BombDefuseStart) {
+	exporter.add("bombDefuseStart", bombDefuseStart.RoundNumber, bombDefuseStart)
+}
+
+func (exporter *ndjsonExporter) OnBombDefused(bombDefused *BombDefused) {
+	exporter.add("bombDefused", bombDefused.RoundNumber, bombDefused)
+}
+
+func (exporter *ndjsonExporter) OnBombExploded(bombExploded *BombExploded) {
+	exporter.add("bombExploded", bombExploded.RoundNumber, bombExploded)
+}
+
+func (exporter *ndjsonExporter) OnHeGrenadeExplode(heGrenadeExplode *HeGrenadeExplode) {
+	exporter.add("heGrenadeExplode", heGrenadeExplode.RoundNumber, heGrenadeExplode)
+}
+
+func (exporter *ndjsonExporter) OnFlashbangExplode(flashbangExplode *FlashbangExplode) {
+	exporter.add("flashbangExplode", flashbangExplode.RoundNumber, flashbangExplode)
+}
+
+func (exporter *ndjsonExporter) OnSmokeStart(smokeStart *SmokeStart) {
+	exporter.add("smokeStart", smokeStart.RoundNumber, smokeStart)
+}
+
+func (exporter *ndjsonExporter) OnDecoyStart(decoyStart *DecoyStart) {
+	exporter.add("decoyStart", decoyStart.RoundNumber, decoyStart)
+}
+
+func (exporter *ndjsonExporter) OnGrenadeBounce(grenadeBounce *GrenadeBounce) {
+	exporter.add("grenadeBounce", grenadeBounce.RoundNumber, grenadeBounce)
+}
+
+func (exporter *ndjsonExporter) OnGrenadeProjectileDestroy(grenadeProjectileDestroy *GrenadeProjectileDestroy) {
+	exporter.add("grenadeProjectileDestroy", grenadeProjectileDestroy.RoundNumber, grenadeProjectileDestroy)
+}
+
+func (exporter *ndjsonExporter) OnHostagePickUpStart(hostagePickUpStart *HostagePickUpStart) {
+	exporter.add("hostagePickUpStart", hostagePickUpStart.RoundNumber, hostagePickUpStart)
+}
+
+func (exporter *ndjsonExporter) OnHostagePickedUp(hostagePickedUp *HostagePickedUp) {
+	exporter.add("hostagePickedUp", hostagePickedUp.RoundNumber, hostagePickedUp)
+}
+
+func (exporter *ndjsonExporter) OnHostageRescued(hostageRescued *HostageRescued) {
+	exporter.add("hostageRescued", hostageRescued.RoundNumber, hostageRescued)
+}
+
+func (exporter *ndjsonExporter) OnChickenDeath(chickenDeath *ChickenDeath) {
+	exporter.add("chickenDeath", chickenDeath.RoundNumber, chickenDeath)
+}
+
+func (exporter *ndjsonExporter) OnChatMessage(chatMessage *ChatMessage) {
+	exporter.add("chatMessage", chatMessage.RoundNumber, chatMessage)
+}
+
+func (exporter *ndjsonExporter) OnPlayerPosition(position *PlayerPosition) {
+	exporter.add("playerPosition", position.RoundNumber, position)
+}
+
+func (exporter *ndjsonExporter) OnGrenadePosition(position *GrenadePosition) {
+	exporter.add("grenadePosition", position.RoundNumber, position)
+}
+
+func (exporter *ndjsonExporter) OnInfernoPosition(position *InfernoPosition) {
+	exporter.add("infernoPosition", position.RoundNumber, position)
+}
+
+func (exporter *ndjsonExporter) OnHostagePosition(position *HostagePosition) {
+	exporter.add("hostagePosition", position.RoundNumber, position)
+}
+
+func (exporter *ndjsonExporter) OnChickenPosition(position *ChickenPosition) {
+	exporter.add("chickenPosition", position.RoundNumber, position)
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/akiver/cs-demo-analyzer/internal/demo"
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+)
+
+func TestNDJSONExportDropsResetRoundEvents(t *testing.T) {
+	exporter, err := newNDJSONExporter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exporter.close()
+
+	match := newMatch(constants.DemoSourceValve, &demo.Demo{FileName: "match"})
+	addKill := func(roundNumber int) {
+		kill := &Kill{RoundNumber: roundNumber}
+		match.Kills = append(match.Kills, kill)
+		exporter.OnKill(kill)
+	}
+
+	addKill(1)
+	exporter.OnRoundEnd(&Round{Number: 1})
+	// The round 2 is restarted after 2 kills, i.e. after a technical pause, and replayed with 1 kill.
+	addKill(2)
+	addKill(2)
+	match.resetRound(2)
+	exporter.OnRoundReset(2)
+	addKill(2)
+	exporter.OnRoundEnd(&Round{Number: 2})
+
+	outputFolderPath := t.TempDir()
+	if err = exporter.export(&match, outputFolderPath); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(filepath.Join(outputFolderPath, "match.ndjson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	killCountByRound := make(map[int]int)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var line struct {
+			Type string `json:"type"`
+			Data struct {
+				RoundNumber int `json:"roundNumber"`
+			} `json:"data"`
+		}
+		if err = json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatal(err)
+		}
+		if line.Type == "kill" {
+			killCountByRound[line.Data.RoundNumber]++
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(&match)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jsonMatch struct {
+		Kills []struct {
+			RoundNumber int `json:"roundNumber"`
+		} `json:"kills"`
+	}
+	if err = json.Unmarshal(data, &jsonMatch); err != nil {
+		t.Fatal(err)
+	}
+	jsonKillCountByRound := make(map[int]int)
+	for _, kill := range jsonMatch.Kills {
+		jsonKillCountByRound[kill.RoundNumber]++
+	}
+
+	for _, roundNumber := range []int{1, 2} {
+		if killCountByRound[roundNumber] != jsonKillCountByRound[roundNumber] {
+			t.Errorf("round %d: expected %d kills like the JSON export, got %d", roundNumber, jsonKillCountByRound[roundNumber], killCountByRound[roundNumber])
+		}
+		if killCountByRound[roundNumber] != 1 {
+			t.Errorf("round %d: expected 1 kill, got %d", roundNumber, killCountByRound[roundNumber])
+		}
+	}
+}
+
+func TestNDJSONExportSortsPlayers(t *testing.T) {
+	exporter, err := newNDJSONExporter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exporter.close()
+
+	match := newMatch(constants.DemoSourceValve, &demo.Demo{FileName: "match"})
+	for _, steamID := range []uint64{30, 4, 1000, 12, 7} {
+		match.PlayersBySteamID[steamID] = &Player{match: &match, SteamID64: steamID, Team: match.TeamA}
+	}
+
+	outputFolderPath := t.TempDir()
+	if err = exporter.export(&match, outputFolderPath); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(filepath.Join(outputFolderPath, "match.ndjson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var steamIDs []uint64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var line struct {
+			Type string `json:"type"`
+			Data struct {
+				SteamID64 uint64 `json:"steamId"`
+			} `json:"data"`
+		}
+		if err = json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatal(err)
+		}
+		if line.Type == "player" {
+			steamIDs = append(steamIDs, line.Data.SteamID64)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedSteamIDs := []uint64{4, 7, 12, 30, 1000}
+	if !slices.Equal(steamIDs, expectedSteamIDs) {
+		t.Errorf("expected players %v, got %v", expectedSteamIDs, steamIDs)
+	}
+}
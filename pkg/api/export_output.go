@@ -0,0 +1,146 @@
+package api
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/akiver/cs-demo-analyzer/internal/compression"
+	"github.com/akiver/cs-demo-analyzer/internal/csv"
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+)
+
+// StdoutOutputPath is the output path to write the export on the standard output.
+// Exports that produce several files (CSV, CSDM) are written as a tar stream.
+const StdoutOutputPath = "-"
+
+// ValidateStdoutExportFormat returns an error if the export format can't be written on the standard output.
+func ValidateStdoutExportFormat(format constants.ExportFormat) error {
+	switch format {
+	case constants.ExportFormatCSV, constants.ExportFormatJSON, constants.ExportFormatCSDM, constants.ExportFormatNDJSON:
+		return nil
+	}
+
+	return errors.New("only the csv, json, csdm and ndjson formats can be written on the standard output")
+}
+
+// createOutputFile creates the file of exports that produce a single file, the compression extension is added to the
+// extension. The file is written on the standard output when the output path is StdoutOutputPath.
+func createOutputFile(match *Match, outputPath string, extension string, fileCompression constants.Compression) (io.WriteCloser, error) {
+	if outputPath == StdoutOutputPath {
+		return compression.NewWriter(os.Stdout, fileCompression)
+	}
+
+	outputFilePath, err := buildOutputFilePath(match, outputPath, extension+compression.Extension(fileCompression))
+	if err != nil {
+		return nil, err
+	}
+
+	return compression.CreateFile(outputFilePath, fileCompression)
+}
+
+// tarStream writes files into a tar archive, files may be added concurrently.
+type tarStream struct {
+	mutex  sync.Mutex
+	writer *tar.Writer
+}
+
+func newTarStream(writer io.Writer) *tarStream {
+	return &tarStream{
+		writer: tar.NewWriter(writer),
+	}
+}
+
+func (stream *tarStream) writeFile(name string, content []byte) error {
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+
+	err := stream.writer.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     int64(len(content)),
+		ModTime:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = stream.writer.Write(content)
+
+	return err
+}
+
+// close writes the end of the archive.
+func (stream *tarStream) close() error {
+	return stream.writer.Close()
+}
+
+// exportOutput is the destination of exports that produce several files, files are written into a folder or into a
+// tar stream when the output is the standard output.
+type exportOutput struct {
+	folderPath  string
+	compression constants.Compression
+	tar         *tarStream
+	mutex       sync.Mutex
+	errs        []error
+}
+
+func newExportOutput(outputPath string, compression constants.Compression, tar *tarStream) (*exportOutput, error) {
+	if tar == nil {
+		if stat, err := os.Stat(outputPath); err != nil || !stat.IsDir() {
+			return nil, errors.New("incorrect output provided, make sure it's a folder that exists and you have write access")
+		}
+	}
+
+	return &exportOutput{
+		folderPath:  outputPath,
+		compression: compression,
+		tar:         tar,
+	}, nil
+}
+
+// writeCSVFile writes the lines into a CSV file, the compression extension is added to the file name.
+// It may be called concurrently.
+func (output *exportOutput) writeCSVFile(fileName string, lines [][]string) {
+	var err error
+	if output.tar == nil {
+		err = csv.WriteLinesIntoCsvFile(output.folderPath+string(os.PathSeparator)+fileName, lines, output.compression)
+	} else {
+		err = output.writeTarCSVFile(fileName, lines)
+	}
+
+	if err != nil {
+		output.mutex.Lock()
+		output.errs = append(output.errs, err)
+		output.mutex.Unlock()
+	}
+}
+
+// writeTarCSVFile writes the CSV file into the tar stream, the size of a file is written before its content in a tar
+// archive so it's built in memory.
+func (output *exportOutput) writeTarCSVFile(fileName string, lines [][]string) error {
+	var content bytes.Buffer
+	writer, err := compression.NewWriter(&content, output.compression)
+	if err != nil {
+		return err
+	}
+
+	if err = errors.Join(csv.WriteLines(writer, lines), writer.Close()); err != nil {
+		return err
+	}
+
+	return output.tar.writeFile(fileName+compression.Extension(output.compression), content.Bytes())
+}
+
+// err returns the errors that occurred while writing files.
+func (output *exportOutput) err() error {
+	output.mutex.Lock()
+	defer output.mutex.Unlock()
+
+	return errors.Join(output.errs...)
+}
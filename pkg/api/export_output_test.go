@@ -0,0 +1,65 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+)
+
+func TestExportOutputReturnsCSVFileErrors(t *testing.T) {
+	folderPath := t.TempDir()
+	output, err := newExportOutput(folderPath, constants.CompressionNone, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The folder is removed after the output has been validated, i.e. when a disk is unmounted during the export.
+	if err = os.Remove(folderPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = exportMatchToCSV(newMatchWithOneOfEachEvent(), output); err == nil {
+		t.Error("expected an error when the CSV files can't be created")
+	}
+}
+
+func TestCSDMExportSkipsExcludedEvents(t *testing.T) {
+	match := newMatchWithOneOfEachEvent()
+	match.Winner = match.TeamA
+	var err error
+	match.eventCategories, err = resolveEventCategories(nil, []constants.EventCategory{constants.EventCategoryShots, constants.EventCategoryGrenades}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	folderPath := t.TempDir()
+	output, err := newExportOutput(folderPath, constants.CompressionNone, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = exportMatchForCSDM(match, output); err != nil {
+		t.Fatal(err)
+	}
+
+	for fileName, isExpected := range map[string]bool{
+		"_match.csv":                 true,
+		"_kills.csv":                 true,
+		"_shots.csv":                 false,
+		"_smokes_start.csv":          false,
+		"_grenade_positions.csv":     false,
+		"_players_flashed.csv":       false,
+		"_chicken_positions.csv":     false,
+		"_players_economy.csv":       true,
+		"_hostage_pick_up_start.csv": true,
+	} {
+		_, err := os.Stat(filepath.Join(folderPath, match.DemoFileName+fileName))
+		if isExpected && err != nil {
+			t.Errorf("expected %s to be written: %v", fileName, err)
+		}
+		if !isExpected && err == nil {
+			t.Errorf("%s of an excluded category written", fileName)
+		}
+	}
+}
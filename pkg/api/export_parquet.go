@@ -0,0 +1,110 @@
+package api
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+var parquetGoTypes = map[exportColumnType]reflect.Type{
+	exportColumnTypeString:    reflect.TypeFor[string](),
+	exportColumnTypeEnum:      reflect.TypeFor[string](),
+	exportColumnTypeInt:       reflect.TypeFor[int64](),
+	exportColumnTypeUint64:    reflect.TypeFor[uint64](),
+	exportColumnTypeFloat32:   reflect.TypeFor[float32](),
+	exportColumnTypeFloat64:   reflect.TypeFor[float64](),
+	exportColumnTypeBool:      reflect.TypeFor[bool](),
+	exportColumnTypeTimestamp: reflect.TypeFor[time.Time](),
+}
+
+// buildParquetRowType returns a struct type with one field per column, the parquet schema is built from its tags.
+// Enums are dictionary-encoded because they have a few distinct values.
+func buildParquetRowType(table exportTable) reflect.Type {
+	fields := make([]reflect.StructField, len(table.columns))
+	for index, column := range table.columns {
+		tag := snakeCaseColumnName(column.name)
+		switch column.columnType {
+		case exportColumnTypeEnum:
+			tag += ",dict"
+		case exportColumnTypeTimestamp:
+			tag += ",timestamp(millisecond)"
+		}
+
+		fields[index] = reflect.StructField{
+			Name: "Column" + strconv.Itoa(index),
+			Type: parquetGoTypes[column.columnType],
+			Tag:  reflect.StructTag(`parquet:"` + tag + `"`),
+		}
+	}
+
+	return reflect.StructOf(fields)
+}
+
+func writeParquetFile(filePath string, table exportTable) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	rowType := buildParquetRowType(table)
+	writer := parquet.NewWriter(
+		file,
+		parquet.SchemaOf(reflect.New(rowType).Interface()),
+		parquet.Compression(&parquet.Snappy),
+	)
+
+	row := reflect.New(rowType)
+	for index := range table.rowCount {
+		for valueIndex, value := range table.row(index) {
+			field := row.Elem().Field(valueIndex)
+			field.Set(reflect.ValueOf(value).Convert(field.Type()))
+		}
+
+		err = writer.Write(row.Interface())
+		if err != nil {
+			return err
+		}
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+func exportMatchToParquet(match *Match, outputPath string) error {
+	if stat, err := os.Stat(outputPath); err != nil || !stat.IsDir() {
+		return errors.New("incorrect output provided, make sure it's a folder that exists and you have write access")
+	}
+
+	outputPath = outputPath + string(os.PathSeparator) + match.DemoFileName
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	var errs []error
+
+	for _, table := range buildExportTables(match) {
+		wg.Add(1)
+		go func(table exportTable) {
+			defer wg.Done()
+			err := writeParquetFile(outputPath+"_"+table.name+".parquet", table)
+			if err != nil {
+				mutex.Lock()
+				errs = append(errs, err)
+				mutex.Unlock()
+			}
+		}(table)
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
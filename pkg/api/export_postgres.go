@@ -0,0 +1,161 @@
+package api
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/akiver/cs-demo-analyzer/internal/converters"
+)
+
+var postgresColumnTypes = map[exportColumnType]string{
+	exportColumnTypeString:    "TEXT",
+	exportColumnTypeEnum:      "TEXT",
+	exportColumnTypeInt:       "BIGINT",
+	exportColumnTypeUint64:    "BIGINT",
+	exportColumnTypeFloat32:   "REAL",
+	exportColumnTypeFloat64:   "DOUBLE PRECISION",
+	exportColumnTypeBool:      "BOOLEAN",
+	exportColumnTypeTimestamp: "TIMESTAMPTZ",
+}
+
+// Value of the columns added to existing tables for the rows that already exist.
+var postgresColumnDefaultValues = map[exportColumnType]string{
+	exportColumnTypeString:    "''",
+	exportColumnTypeEnum:      "''",
+	exportColumnTypeInt:       "0",
+	exportColumnTypeUint64:    "0",
+	exportColumnTypeFloat32:   "0",
+	exportColumnTypeFloat64:   "0",
+	exportColumnTypeBool:      "FALSE",
+	exportColumnTypeTimestamp: "'epoch'",
+}
+
+var postgresCopyEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// formatPostgresCopyValue returns the value in the text format of the COPY command.
+func formatPostgresCopyValue(value any) string {
+	switch value := sqlValue(value).(type) {
+	case string:
+		return postgresCopyEscaper.Replace(value)
+	case int64:
+		return converters.Int64ToString(value)
+	case float64:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	case bool:
+		if value {
+			return "t"
+		}
+		return "f"
+	}
+
+	panic("unsupported COPY value type")
+}
+
+func writePostgresCopyFile(filePath string, table exportTable) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for index := range table.rowCount {
+		for valueIndex, value := range table.row(index) {
+			if valueIndex > 0 {
+				writer.WriteByte('\t')
+			}
+			writer.WriteString(formatPostgresCopyValue(value))
+		}
+		writer.WriteByte('\n')
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+// buildPostgresSchema returns the statements that create the tables, the columns added since a table was created by a
+// previous version of csda are added to it, i.e. content_hash, so COPY commands don't fail on existing databases.
+func buildPostgresSchema(tables []exportTable) []string {
+	var statements []string
+	for _, table := range tables {
+		// The first statement creates the table, the next ones create its indexes that may use added columns.
+		tableStatements := buildSQLSchema([]exportTable{table}, postgresColumnTypes)
+		statements = append(statements, tableStatements[0])
+		for _, column := range table.columns {
+			statements = append(statements, fmt.Sprintf(
+				"ALTER TABLE %q ADD COLUMN IF NOT EXISTS %q %s NOT NULL DEFAULT %s",
+				sqlTableName(table),
+				sqlColumnName(table, column),
+				postgresColumnTypes[column.columnType],
+				postgresColumnDefaultValues[column.columnType],
+			))
+		}
+		statements = append(statements, tableStatements[1:]...)
+	}
+
+	return statements
+}
+
+func quotePostgresLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// exportMatchToPostgres writes the files to load the match into a PostgreSQL database:
+//   - schema.sql creates the tables if they don't exist and adds the missing columns, it's the same for all demos
+//   - one COPY text file per table
+//   - a load script that replaces the rows of the match inside a transaction, it must be run with psql from the output
+//     folder because COPY files paths are relative
+func exportMatchToPostgres(match *Match, outputPath string) error {
+	if stat, err := os.Stat(outputPath); err != nil || !stat.IsDir() {
+		return errors.New("incorrect output provided, make sure it's a folder that exists and you have write access")
+	}
+
+	folderPath := outputPath + string(os.PathSeparator)
+	statements := buildPostgresSchema(buildAllExportTables(match))
+	err := os.WriteFile(folderPath+"schema.sql", []byte(strings.Join(statements, ";\n")+";\n"), 0644)
+	if err != nil {
+		return err
+	}
+
+	var script strings.Builder
+	script.WriteString(fmt.Sprintf("-- Load the demo %s, run it from this folder: psql -f %s_load.sql\n", match.DemoFileName, match.DemoFileName))
+	script.WriteString("\\set ON_ERROR_STOP on\n")
+	script.WriteString("BEGIN;\n")
+	// Hide the notices of the columns that already exist.
+	script.WriteString("SET LOCAL client_min_messages = warning;\n")
+	script.WriteString("\\ir schema.sql\n")
+	// Rows of the other tables are deleted too because their foreign key cascades.
+	script.WriteString(fmt.Sprintf("DELETE FROM \"matches\" WHERE \"checksum\" = %s;\n", quotePostgresLiteral(match.Checksum)))
+
+	// The match table is the first one, it's loaded before the tables that reference it.
+	for _, table := range buildExportTables(match) {
+		fileName := match.DemoFileName + "_" + table.name + ".tsv"
+		err = writePostgresCopyFile(folderPath+fileName, table)
+		if err != nil {
+			return err
+		}
+
+		columnNames := make([]string, len(table.columns))
+		for index, column := range table.columns {
+			columnNames[index] = fmt.Sprintf("%q", sqlColumnName(table, column))
+		}
+		script.WriteString(fmt.Sprintf(
+			"\\copy %q (%s) FROM %s\n",
+			sqlTableName(table),
+			strings.Join(columnNames, ", "),
+			quotePostgresLiteral(fileName),
+		))
+	}
+
+	script.WriteString("COMMIT;\n")
+
+	return os.WriteFile(folderPath+match.DemoFileName+"_load.sql", []byte(script.String()), 0644)
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestPostgresSchemaAddsCopiedColumns checks that every column loaded by the COPY commands is added to the tables
+// created by a previous version of csda.
+func TestPostgresSchemaAddsCopiedColumns(t *testing.T) {
+	match := newMatchWithOneOfEachEvent()
+	match.Winner = match.TeamA
+	match.DemoFileName = "match"
+	folderPath := t.TempDir()
+	if err := exportMatchToPostgres(match, folderPath); err != nil {
+		t.Fatal(err)
+	}
+
+	schema, err := os.ReadFile(filepath.Join(folderPath, "schema.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loadScript, err := os.ReadFile(filepath.Join(folderPath, "match_load.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	copyCommands := regexp.MustCompile(`\\copy "(\w+)" \(([^)]*)\)`).FindAllStringSubmatch(string(loadScript), -1)
+	if len(copyCommands) == 0 {
+		t.Fatal("no COPY commands found in the load script")
+	}
+	statements := strings.Split(string(schema), ";\n")
+	statementIndex := func(prefix string) int {
+		return slices.IndexFunc(statements, func(statement string) bool {
+			return strings.HasPrefix(statement, prefix)
+		})
+	}
+	for _, command := range copyCommands {
+		tableName := command[1]
+		createIndex := statementIndex(`CREATE TABLE IF NOT EXISTS "`+tableName+`"`)
+		for _, columnName := range strings.Split(command[2], ", ") {
+			alterIndex := statementIndex(`ALTER TABLE "`+tableName+`" ADD COLUMN IF NOT EXISTS `+columnName+" ")
+			if alterIndex == -1 {
+				t.Errorf("column %s of the table %s is not added to existing tables", columnName, tableName)
+			} else if alterIndex < createIndex {
+				t.Errorf("column %s of the table %s is added before the table is created", columnName, tableName)
+			}
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// protobufMessages contains the protobuf message of each export table, the field number of a message in the Record oneof
+// is its position in the list + 1. New messages must be added at the end to not break existing consumers.
+// The field number of a column is its position in the table columns + 1, new columns must be added at the end too.
+var protobufMessages = []struct {
+	tableName   string
+	messageName string
+}{
+	{"match", "Match"},
+	{"teams", "Team"},
+	{"players", "Player"},
+	{"rounds", "Round"},
+	{"kills", "Kill"},
+	{"damages", "Damage"},
+	{"shots", "Shot"},
+	{"clutches", "Clutch"},
+	{"players_economy", "PlayerEconomy"},
+	{"players_buy", "PlayerBuy"},
+	{"players_flashed", "PlayerFlashed"},
+	{"bombs_plant_start", "BombPlantStart"},
+	{"bombs_planted", "BombPlanted"},
+	{"bombs_defuse_start", "BombDefuseStart"},
+	{"bombs_defused", "BombDefused"},
+	{"bombs_exploded", "BombExploded"},
+	{"he_grenades_explode", "HeGrenadeExplode"},
+	{"flashbangs_explode", "FlashbangExplode"},
+	{"smokes_start", "SmokeStart"},
+	{"decoys_start", "DecoyStart"},
+	{"grenade_bounces", "GrenadeBounce"},
+	{"grenade_projectiles_destroy", "GrenadeProjectileDestroy"},
+	{"hostage_pick_up_start", "HostagePickUpStart"},
+	{"hostage_picked_up", "HostagePickedUp"},
+	{"hostage_rescued", "HostageRescued"},
+	{"chicken_deaths", "ChickenDeath"},
+	{"chat_messages", "ChatMessage"},
+	{"positions", "PlayerPosition"},
+	{"grenade_positions", "GrenadePosition"},
+	{"inferno_positions", "InfernoPosition"},
+	{"hostage_positions", "HostagePosition"},
+	{"chicken_positions", "ChickenPosition"},
+	{"diagnostics", "Diagnostic"},
+}
+
+var protobufFieldTypes = map[exportColumnType]string{
+	exportColumnTypeString:    "string",
+	exportColumnTypeEnum:      "string",
+	exportColumnTypeInt:       "int64",
+	exportColumnTypeUint64:    "fixed64",
+	exportColumnTypeFloat32:   "float",
+	exportColumnTypeFloat64:   "double",
+	exportColumnTypeBool:      "bool",
+	exportColumnTypeTimestamp: "google.protobuf.Timestamp",
+}
+
+// protobufFieldName returns the name of the column in its message, names can't start with a digit, i.e. "1v1" ->
+// "count_1v1".
+func protobufFieldName(table exportTable, column exportColumn) string {
+	name := sqlColumnName(table, column)
+	if unicode.IsDigit(rune(name[0])) {
+		return "count_" + name
+	}
+
+	return name
+}
+
+// protobufOneofFieldName returns the snake case name of a message, i.e. "PlayerPosition" -> "player_position".
+func protobufOneofFieldName(messageName string) string {
+	var builder strings.Builder
+	for index, character := range messageName {
+		if unicode.IsUpper(character) {
+			if index > 0 {
+				builder.WriteRune('_')
+			}
+			character = unicode.ToLower(character)
+		}
+		builder.WriteRune(character)
+	}
+
+	return builder.String()
+}
+
+// buildProtobufSchema returns the content of the .proto file that describes the protobuf export.
+func buildProtobufSchema() string {
+	tablesByName := make(map[string]exportTable)
+	for _, table := range buildAllExportTables(&Match{}) {
+		tablesByName[table.name] = table
+	}
+
+	var schema strings.Builder
+	schema.WriteString("// This file is generated from the export tables of the package pkg/api, don't edit it manually.\n")
+	schema.WriteString("// Run \"go test ./pkg/api -run TestProtobufSchema -update\" to update it.\n")
+	schema.WriteString("syntax = \"proto3\";\n\n")
+	schema.WriteString("package csda.v1;\n\n")
+	schema.WriteString("import \"google/protobuf/timestamp.proto\";\n\n")
+	schema.WriteString("option go_package = \"github.com/akiver/cs-demo-analyzer/proto/csda/v1;csdav1\";\n\n")
+	schema.WriteString("// Record is an entry of the protobuf export, each record is prefixed with its size encoded as a varint.\n")
+	schema.WriteString("// The first record is the match, followed by teams, players, rounds and events.\n")
+	schema.WriteString("message Record {\n  oneof entity {\n")
+	for index, message := range protobufMessages {
+		schema.WriteString(fmt.Sprintf("    %s %s = %d;\n", message.messageName, protobufOneofFieldName(message.messageName), index+1))
+	}
+	schema.WriteString("  }\n}\n")
+
+	for _, message := range protobufMessages {
+		table := tablesByName[message.tableName]
+		schema.WriteString(fmt.Sprintf("\nmessage %s {\n", message.messageName))
+		for index, column := range table.columns {
+			schema.WriteString(fmt.Sprintf(
+				"  %s %s = %d;\n",
+				protobufFieldTypes[column.columnType],
+				protobufFieldName(table, column),
+				index+1,
+			))
+		}
+		schema.WriteString("}\n")
+	}
+
+	return schema.String()
+}
+
+// appendProtobufValue appends the field to the message, zero values are omitted like proto3 encoders do.
+func appendProtobufValue(message []byte, number protowire.Number, value any) []byte {
+	switch value := value.(type) {
+	case string:
+		if value != "" {
+			message = protowire.AppendTag(message, number, protowire.BytesType)
+			message = protowire.AppendString(message, value)
+		}
+	case int:
+		if value != 0 {
+			message = protowire.AppendTag(message, number, protowire.VarintType)
+			message = protowire.AppendVarint(message, uint64(value))
+		}
+	case int64:
+		if value != 0 {
+			message = protowire.AppendTag(message, number, protowire.VarintType)
+			message = protowire.AppendVarint(message, uint64(value))
+		}
+	case uint64:
+		if value != 0 {
+			message = protowire.AppendTag(message, number, protowire.Fixed64Type)
+			message = protowire.AppendFixed64(message, value)
+		}
+	case float32:
+		if value != 0 {
+			message = protowire.AppendTag(message, number, protowire.Fixed32Type)
+			message = protowire.AppendFixed32(message, math.Float32bits(value))
+		}
+	case float64:
+		if value != 0 {
+			message = protowire.AppendTag(message, number, protowire.Fixed64Type)
+			message = protowire.AppendFixed64(message, math.Float64bits(value))
+		}
+	case bool:
+		if value {
+			message = protowire.AppendTag(message, number, protowire.VarintType)
+			message = protowire.AppendVarint(message, 1)
+		}
+	case time.Time:
+		var timestamp []byte
+		timestamp = appendProtobufValue(timestamp, 1, value.Unix())
+		timestamp = appendProtobufValue(timestamp, 2, int64(value.Nanosecond()))
+		message = protowire.AppendTag(message, number, protowire.BytesType)
+		message = protowire.AppendBytes(message, timestamp)
+	default:
+		panic("unsupported protobuf value type")
+	}
+
+	return message
+}
+
+// protobufMessageNumber returns the field number of the message of the table in the Record oneof.
+func protobufMessageNumber(tableName string) protowire.Number {
+	for index, message := range protobufMessages {
+		if message.tableName == tableName {
+			return protowire.Number(index + 1)
+		}
+	}
+
+	panic("no protobuf message for table " + tableName)
+}
+
+// appendProtobufRecord appends the Record of the row of the table, without its size prefix.
+func appendProtobufRecord(record []byte, message []byte, table exportTable, index int) ([]byte, []byte) {
+	message = message[:0]
+	for valueIndex, value := range table.row(index) {
+		message = appendProtobufValue(message, protowire.Number(valueIndex+1), value)
+	}
+
+	record = protowire.AppendTag(record, protobufMessageNumber(table.name), protowire.BytesType)
+	record = protowire.AppendBytes(record, message)
+
+	return record, message
+}
+
+// WriteProtobufRecords calls write with each Record of the protobuf export of the match, in the same order as the
+// export file but without the size prefix. The record is only valid until write returns.
+func WriteProtobufRecords(match *Match, write func(record []byte) error) error {
+	var message, record []byte
+	for _, table := range buildExportTables(match) {
+		for index := range table.rowCount {
+			record, message = appendProtobufRecord(record[:0], message, table, index)
+			err := write(record)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// MarshalProtobufRecord returns the Record of a match, round, kill, damage or player position as written by the
+// protobuf export. matchChecksum is the value of the match checksum field of rounds and events.
+func MarshalProtobufRecord(matchChecksum string, entity any) ([]byte, error) {
+	var table exportTable
+	switch entity := entity.(type) {
+	case *Match:
+		table = buildMatchTable(entity)
+	case *Round:
+		table = buildRoundsTable(&Match{Checksum: matchChecksum, Rounds: []*Round{entity}})
+	case *Kill:
+		table = buildKillsTable(&Match{Checksum: matchChecksum, Kills: []*Kill{entity}})
+	case *Damage:
+		table = buildDamagesTable(&Match{Checksum: matchChecksum, Damages: []*Damage{entity}})
+	case *PlayerPosition:
+		table = buildPlayerPositionsTable(&Match{Checksum: matchChecksum, PlayerPositions: []*PlayerPosition{entity}})
+	default:
+		return nil, fmt.Errorf("unsupported protobuf record type %T", entity)
+	}
+
+	record, _ := appendProtobufRecord(nil, nil, table, 0)
+
+	return record, nil
+}
+
+func exportMatchToProtobuf(match *Match, outputPath string) error {
+	outputFilePath, err := buildOutputFilePath(match, outputPath, ".pb")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(outputFilePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	err = WriteProtobufRecords(match, func(record []byte) error {
+		_, err := writer.Write(protowire.AppendVarint(nil, uint64(len(record))))
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(record)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return file.Close()
+}
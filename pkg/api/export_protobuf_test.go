@@ -0,0 +1,32 @@
+package api
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the generated schema files")
+
+// TestProtobufSchema fails when the export tables changed without updating the .proto file.
+// Pass the -update flag to update it.
+func TestProtobufSchema(t *testing.T) {
+	protoFilePath := filepath.Join("..", "..", "proto", "csda", "v1", "match.proto")
+	schema := buildProtobufSchema()
+	if *update {
+		err := os.WriteFile(protoFilePath, []byte(schema), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, err := os.ReadFile(protoFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != schema {
+		t.Errorf("%s is outdated, run \"go test ./pkg/api -run TestProtobufSchema -update\" to update it", protoFilePath)
+	}
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sqlTableName returns the name of the SQL table of an export table.
+func sqlTableName(table exportTable) string {
+	if table.name == "match" {
+		return "matches"
+	}
+
+	return table.name
+}
+
+// sqlColumnName returns the snake case name of the column with consistent names for the columns used to join tables, i.e.
+// "round" -> "round_number" and "killer steamid" -> "killer_steam_id".
+func sqlColumnName(table exportTable, column exportColumn) string {
+	name := snakeCaseColumnName(column.name)
+	if name == "round" || (table.name == "rounds" && name == "number") {
+		return "round_number"
+	}
+
+	if strings.HasSuffix(name, "steamid") {
+		return strings.TrimSuffix(name, "steamid") + "steam_id"
+	}
+
+	return name
+}
+
+// isSQLIndexedColumn indicates if an index should be created on the column, columns used to filter events are indexed.
+func isSQLIndexedColumn(columnName string) bool {
+	return columnName == "tick" || columnName == "round_number" || columnName == "match_checksum" ||
+		strings.HasSuffix(columnName, "steam_id")
+}
+
+// sqlValue converts a value of an export table to a value supported by SQL drivers.
+// Steam IDs fit in a signed 64-bit integer, dates are written in RFC 3339 format.
+func sqlValue(value any) any {
+	switch value := value.(type) {
+	case int:
+		return int64(value)
+	case uint64:
+		return int64(value)
+	case float32:
+		// Keep the shortest representation of the value, i.e. 0.1 instead of 0.10000000149011612.
+		value64, _ := strconv.ParseFloat(strconv.FormatFloat(float64(value), 'g', -1, 32), 64)
+		return value64
+	case time.Time:
+		return value.Format(time.RFC3339)
+	}
+
+	return value
+}
+
+// buildSQLSchema returns the statements that create the tables and their indexes if they don't exist.
+// columnTypes contains the SQL type of each column type for the database.
+func buildSQLSchema(tables []exportTable, columnTypes map[exportColumnType]string) []string {
+	var statements []string
+	for _, table := range tables {
+		tableName := sqlTableName(table)
+		var columns []string
+		var indexedColumns []string
+		for _, column := range table.columns {
+			columnName := sqlColumnName(table, column)
+			definition := fmt.Sprintf("%q %s NOT NULL", columnName, columnTypes[column.columnType])
+			if tableName == "matches" && columnName == "checksum" {
+				definition += " PRIMARY KEY"
+			} else if columnName == "match_checksum" {
+				definition += ` REFERENCES "matches" ("checksum") ON DELETE CASCADE`
+			}
+			columns = append(columns, definition)
+
+			if isSQLIndexedColumn(columnName) {
+				indexedColumns = append(indexedColumns, columnName)
+			}
+		}
+
+		statements = append(statements, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %q (%s)", tableName, strings.Join(columns, ", ")))
+		for _, columnName := range indexedColumns {
+			statements = append(statements, fmt.Sprintf(
+				"CREATE INDEX IF NOT EXISTS %q ON %q (%q)",
+				"idx_"+tableName+"_"+columnName,
+				tableName,
+				columnName,
+			))
+		}
+	}
+
+	return statements
+}
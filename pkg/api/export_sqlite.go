@@ -0,0 +1,220 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+var sqliteColumnTypes = map[exportColumnType]string{
+	exportColumnTypeString:    "TEXT",
+	exportColumnTypeEnum:      "TEXT",
+	exportColumnTypeInt:       "INTEGER",
+	exportColumnTypeUint64:    "INTEGER",
+	exportColumnTypeFloat32:   "REAL",
+	exportColumnTypeFloat64:   "REAL",
+	exportColumnTypeBool:      "INTEGER",
+	exportColumnTypeTimestamp: "TEXT",
+}
+
+// sqliteSchemaVersion is stored in the user_version pragma of the database, it must be incremented when the schema
+// changes. Columns added since a previous version are added to existing databases when a demo is exported into it.
+// 1: initial schema
+// 2: content_hash column of the matches table
+const sqliteSchemaVersion = 2
+
+// Value of the columns added by a migration for the rows that already exist.
+var sqliteColumnDefaultValues = map[string]string{
+	"TEXT":    "''",
+	"INTEGER": "0",
+	"REAL":    "0",
+}
+
+// buildSQLiteFilePath returns the path of the database, it's created next to the demo or in the output folder.
+// When the output is a file path, the database is shared between demos and created if it doesn't exist.
+func buildSQLiteFilePath(match *Match, outputPath string) (string, error) {
+	if outputPath != "" && filepath.Ext(outputPath) != "" {
+		if _, err := os.Stat(outputPath); os.IsNotExist(err) {
+			return outputPath, nil
+		}
+	}
+
+	return buildOutputFilePath(match, outputPath, ".sqlite")
+}
+
+func insertSQLiteTable(transaction *sql.Tx, table exportTable) error {
+	columnNames := make([]string, len(table.columns))
+	placeholders := make([]string, len(table.columns))
+	for index, column := range table.columns {
+		columnNames[index] = fmt.Sprintf("%q", sqlColumnName(table, column))
+		placeholders[index] = "?"
+	}
+
+	statement, err := transaction.Prepare(fmt.Sprintf(
+		"INSERT INTO %q (%s) VALUES (%s)",
+		sqlTableName(table),
+		strings.Join(columnNames, ", "),
+		strings.Join(placeholders, ", "),
+	))
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	for index := range table.rowCount {
+		values := table.row(index)
+		for valueIndex, value := range values {
+			values[valueIndex] = sqlValue(value)
+		}
+
+		_, err = statement.Exec(values...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateSQLiteSchema adds the missing columns to the tables of a database created by a previous version of csda.
+// It fails if the database has been created by a newer version or if a column has been removed or renamed since then
+// because rows can't be inserted into it anymore.
+func migrateSQLiteSchema(transaction *sql.Tx, tables []exportTable, databasePath string) error {
+	var version int
+	if err := transaction.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+
+	if version > sqliteSchemaVersion {
+		return fmt.Errorf(
+			"the database %s uses the schema version %d but this version of csda supports up to the version %d, upgrade csda or export into a new database",
+			databasePath,
+			version,
+			sqliteSchemaVersion,
+		)
+	}
+
+	if version == sqliteSchemaVersion {
+		return nil
+	}
+
+	for _, table := range tables {
+		tableName := sqlTableName(table)
+		rows, err := transaction.Query("SELECT name FROM pragma_table_info(?)", tableName)
+		if err != nil {
+			return err
+		}
+		existingColumnNames := make(map[string]bool)
+		for rows.Next() {
+			var columnName string
+			if err = rows.Scan(&columnName); err != nil {
+				rows.Close()
+				return err
+			}
+			existingColumnNames[columnName] = true
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+
+		// The table doesn't exist yet, it's created with the current schema.
+		if len(existingColumnNames) == 0 {
+			continue
+		}
+
+		for _, column := range table.columns {
+			columnName := sqlColumnName(table, column)
+			if existingColumnNames[columnName] {
+				delete(existingColumnNames, columnName)
+				continue
+			}
+
+			columnType := sqliteColumnTypes[column.columnType]
+			_, err = transaction.Exec(fmt.Sprintf(
+				"ALTER TABLE %q ADD COLUMN %q %s NOT NULL DEFAULT %s",
+				tableName,
+				columnName,
+				columnType,
+				sqliteColumnDefaultValues[columnType],
+			))
+			if err != nil {
+				return err
+			}
+		}
+
+		for columnName := range existingColumnNames {
+			return fmt.Errorf(
+				"the column %q of the table %q of the database %s is not part of the schema version %d, export into a new database",
+				columnName,
+				tableName,
+				databasePath,
+				sqliteSchemaVersion,
+			)
+		}
+	}
+
+	return nil
+}
+
+// exportMatchToSQLite writes the match into a SQLite database. Rows of a match previously exported with the same
+// checksum are replaced so exporting a demo again doesn't duplicate it.
+func exportMatchToSQLite(match *Match, outputPath string) error {
+	databasePath, err := buildSQLiteFilePath(match, outputPath)
+	if err != nil {
+		return err
+	}
+
+	// Demos may be exported concurrently into the same database, wait for the lock instead of failing.
+	database, err := sql.Open("sqlite", databasePath+"?_pragma=busy_timeout(60000)&_txlock=immediate")
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	transaction, err := database.Begin()
+	if err != nil {
+		return err
+	}
+	defer transaction.Rollback()
+
+	allTables := buildAllExportTables(match)
+	if err = migrateSQLiteSchema(transaction, allTables, databasePath); err != nil {
+		return err
+	}
+
+	for _, statement := range buildSQLSchema(allTables, sqliteColumnTypes) {
+		if _, err = transaction.Exec(statement); err != nil {
+			return err
+		}
+	}
+
+	if _, err = transaction.Exec(fmt.Sprintf("PRAGMA user_version = %d", sqliteSchemaVersion)); err != nil {
+		return err
+	}
+
+	// Tables that reference the match are deleted first, the match is deleted last.
+	for index := len(allTables) - 1; index >= 0; index-- {
+		table := allTables[index]
+		checksumColumn := "match_checksum"
+		if table.name == "match" {
+			checksumColumn = "checksum"
+		}
+		_, err = transaction.Exec(fmt.Sprintf("DELETE FROM %q WHERE %q = ?", sqlTableName(table), checksumColumn), match.Checksum)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, table := range buildExportTables(match) {
+		if err = insertSQLiteTable(transaction, table); err != nil {
+			return err
+		}
+	}
+
+	return transaction.Commit()
+}
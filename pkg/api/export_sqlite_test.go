@@ -0,0 +1,90 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// createSQLiteDatabase creates a database with the schema of the tables and the given user_version.
+func createSQLiteDatabase(t *testing.T, tables []exportTable, version int) string {
+	databasePath := filepath.Join(t.TempDir(), "demos.sqlite")
+	database, err := sql.Open("sqlite", databasePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	statements := append(buildSQLSchema(tables, sqliteColumnTypes), fmt.Sprintf("PRAGMA user_version = %d", version))
+	for _, statement := range statements {
+		if _, err = database.Exec(statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return databasePath
+}
+
+func TestSQLiteSchemaMigration(t *testing.T) {
+	match := newMatchWithOneOfEachEvent()
+	match.Checksum = "checksum"
+	match.ContentHash = "content hash"
+
+	// The version 1 of the schema has no content hash, databases created at that time have no user_version.
+	tables := buildAllExportTables(match)
+	tables[0].columns = slices.DeleteFunc(slices.Clone(tables[0].columns), func(column exportColumn) bool {
+		return column.name == "content hash"
+	})
+	databasePath := createSQLiteDatabase(t, tables, 0)
+
+	if err := exportMatchToSQLite(match, databasePath); err != nil {
+		t.Fatal(err)
+	}
+
+	database, err := sql.Open("sqlite", databasePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	var version int
+	if err = database.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version != sqliteSchemaVersion {
+		t.Errorf("expected the schema version %d, got %d", sqliteSchemaVersion, version)
+	}
+
+	var contentHash string
+	if err = database.QueryRow(`SELECT "content_hash" FROM "matches" WHERE "checksum" = ?`, match.Checksum).Scan(&contentHash); err != nil {
+		t.Fatal(err)
+	}
+	if contentHash != match.ContentHash {
+		t.Errorf("expected the content hash %q, got %q", match.ContentHash, contentHash)
+	}
+}
+
+func TestSQLiteSchemaNewerVersion(t *testing.T) {
+	match := newMatchWithOneOfEachEvent()
+	databasePath := createSQLiteDatabase(t, buildAllExportTables(match), sqliteSchemaVersion+1)
+
+	err := exportMatchToSQLite(match, databasePath)
+	if err == nil || !strings.Contains(err.Error(), "upgrade csda") {
+		t.Errorf("expected an error about the schema version, got %v", err)
+	}
+}
+
+func TestSQLiteSchemaRemovedColumn(t *testing.T) {
+	match := newMatchWithOneOfEachEvent()
+	tables := buildAllExportTables(match)
+	tables[0].columns = append(slices.Clone(tables[0].columns), exportColumn{"removed column", exportColumnTypeString})
+	databasePath := createSQLiteDatabase(t, tables, 1)
+
+	err := exportMatchToSQLite(match, databasePath)
+	if err == nil || !strings.Contains(err.Error(), "removed_column") {
+		t.Errorf("expected an error about the removed column, got %v", err)
+	}
+}
@@ -0,0 +1,1687 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/akiver/cs-demo-analyzer/internal/slice"
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+	"github.com/markus-wa/demoinfocs-golang/v4/pkg/demoinfocs/common"
+)
+
+// exportColumnType is the type of the values of a column, exports that keep values typed rely on it.
+type exportColumnType int
+
+const (
+	exportColumnTypeString exportColumnType = iota
+	exportColumnTypeEnum                    // String with a small set of possible values such as weapon names
+	exportColumnTypeInt                     // int or int64 values
+	exportColumnTypeUint64
+	exportColumnTypeFloat32
+	exportColumnTypeFloat64
+	exportColumnTypeBool
+	exportColumnTypeTimestamp // time.Time values
+)
+
+type exportColumn struct {
+	name       string
+	columnType exportColumnType
+}
+
+// exportTable describes a file of the exports that write one file per entity (CSV, Parquet...).
+type exportTable struct {
+	name     string // Suffix of the file name, i.e. "kills" for myDemo_kills.csv
+	columns  []exportColumn
+	rowCount int
+	row      func(index int) []any // Values are in the same order as columns
+}
+
+func newExportTable[T any](name string, columns []exportColumn, items []T, row func(item T) []any) exportTable {
+	return exportTable{
+		name:     name,
+		columns:  columns,
+		rowCount: len(items),
+		row: func(index int) []any {
+			return row(items[index])
+		},
+	}
+}
+
+// exportTableBuilders are the builders of the tables that are exported only if all their events categories have been
+// collected, tables of the match, teams, players and rounds are always exported.
+var exportTableBuilders = []struct {
+	categories []constants.EventCategory
+	build      func(match *Match) exportTable
+}{
+	{nil, buildMatchTable},
+	{nil, buildTeamsTable},
+	{nil, buildPlayersTable},
+	{nil, buildRoundsTable},
+	{[]constants.EventCategory{constants.EventCategoryPositions}, buildPlayerPositionsTable},
+	{[]constants.EventCategory{constants.EventCategoryShots}, buildShotsTable},
+	{[]constants.EventCategory{constants.EventCategoryEconomy}, buildRoundEconomiesTable},
+	{[]constants.EventCategory{constants.EventCategoryEconomy}, buildPlayersBuyTable},
+	{[]constants.EventCategory{constants.EventCategoryClutches}, buildClutchesTable},
+	{[]constants.EventCategory{constants.EventCategoryChickens}, buildChickenDeathsTable},
+	{[]constants.EventCategory{constants.EventCategoryDamages}, buildDamagesTable},
+	{[]constants.EventCategory{constants.EventCategoryKills}, buildKillsTable},
+	{[]constants.EventCategory{constants.EventCategoryBombs}, buildBombsPlantedTable},
+	{[]constants.EventCategory{constants.EventCategoryBombs}, buildBombsDefuseStartTable},
+	{[]constants.EventCategory{constants.EventCategoryBombs}, buildBombsDefusedTable},
+	{[]constants.EventCategory{constants.EventCategoryBombs}, buildBombsExplodedTable},
+	{[]constants.EventCategory{constants.EventCategoryBombs}, buildBombsPlantStartTable},
+	{[]constants.EventCategory{constants.EventCategoryGrenades}, buildPlayersFlashedTable},
+	{[]constants.EventCategory{constants.EventCategoryGrenades}, buildGrenadeBouncesTable},
+	{[]constants.EventCategory{constants.EventCategoryGrenades}, buildGrenadeProjectilesDestroyTable},
+	{[]constants.EventCategory{constants.EventCategoryGrenades}, buildSmokesStartTable},
+	{[]constants.EventCategory{constants.EventCategoryGrenades}, buildHeGrenadesExplodeTable},
+	{[]constants.EventCategory{constants.EventCategoryGrenades}, buildFlashbangsExplodeTable},
+	{[]constants.EventCategory{constants.EventCategoryGrenades}, buildDecoysStartTable},
+	{[]constants.EventCategory{constants.EventCategoryChat}, buildChatMessagesTable},
+	{[]constants.EventCategory{constants.EventCategoryHostages}, buildHostagePickUpStartTable},
+	{[]constants.EventCategory{constants.EventCategoryHostages}, buildHostagePickedUpTable},
+	{[]constants.EventCategory{constants.EventCategoryHostages}, buildHostageRescuedTable},
+	// Positions of chickens, grenades and hostages are collected only if their category is collected too.
+	{[]constants.EventCategory{constants.EventCategoryPositions, constants.EventCategoryChickens}, buildChickenPositionsTable},
+	{[]constants.EventCategory{constants.EventCategoryPositions, constants.EventCategoryGrenades}, buildGrenadePositionsTable},
+	{[]constants.EventCategory{constants.EventCategoryPositions, constants.EventCategoryGrenades}, buildInfernoPositionsTable},
+	{[]constants.EventCategory{constants.EventCategoryPositions, constants.EventCategoryHostages}, buildHostagePositionsTable},
+	{nil, buildDiagnosticsTable},
+}
+
+// buildExportTables returns the tables of the match, tables of events categories that have not been collected are
+// omitted.
+func buildExportTables(match *Match) []exportTable {
+	var tables []exportTable
+	for _, builder := range exportTableBuilders {
+		if !match.areEventCategoriesExported(builder.categories) {
+			continue
+		}
+		tables = append(tables, builder.build(match))
+	}
+
+	return tables
+}
+
+// buildAllExportTables returns all the tables, even the ones of events categories that have not been collected.
+// Exports that create a schema use it to always create the same tables.
+func buildAllExportTables(match *Match) []exportTable {
+	tables := make([]exportTable, len(exportTableBuilders))
+	for index, builder := range exportTableBuilders {
+		tables[index] = builder.build(match)
+	}
+
+	return tables
+}
+
+// snakeCaseColumnName returns a name usable without quoting by query engines, i.e. "hs %" -> "hs_percent".
+func snakeCaseColumnName(name string) string {
+	name = strings.ReplaceAll(name, "%", "percent")
+	var builder strings.Builder
+	for _, character := range name {
+		if unicode.IsLetter(character) || unicode.IsDigit(character) {
+			builder.WriteRune(unicode.ToLower(character))
+		} else if builder.Len() > 0 && !strings.HasSuffix(builder.String(), "_") {
+			builder.WriteRune('_')
+		}
+	}
+
+	return strings.TrimSuffix(builder.String(), "_")
+}
+
+func buildMatchTable(match *Match) exportTable {
+	winnerName := ""
+	winnerSide := common.TeamUnassigned
+	if match.Winner != nil {
+		winnerName = match.Winner.Name
+		winnerSide = *match.Winner.CurrentSide
+	}
+
+	return newExportTable(
+		"match",
+		[]exportColumn{
+			{"checksum", exportColumnTypeString},
+			{"game", exportColumnTypeEnum},
+			{"demo path", exportColumnTypeString},
+			{"demo name", exportColumnTypeString},
+			{"date", exportColumnTypeTimestamp},
+			{"source", exportColumnTypeEnum},
+			{"type", exportColumnTypeEnum},
+			{"share code", exportColumnTypeString},
+			{"map", exportColumnTypeString},
+			{"server name", exportColumnTypeString},
+			{"client name", exportColumnTypeString},
+			{"tick count", exportColumnTypeInt},
+			{"tickrate", exportColumnTypeFloat64},
+			{"framerate", exportColumnTypeFloat64},
+			{"game type", exportColumnTypeEnum},
+			{"game mode", exportColumnTypeEnum},
+			{"game mode str", exportColumnTypeEnum},
+			{"is ranked", exportColumnTypeBool},
+			{"duration", exportColumnTypeFloat64},
+			{"network protocol", exportColumnTypeInt},
+			{"build number", exportColumnTypeInt},
+			{"kill count", exportColumnTypeInt},
+			{"assist count", exportColumnTypeInt},
+			{"death count", exportColumnTypeInt},
+			{"shot count", exportColumnTypeInt},
+			{"winner name", exportColumnTypeString},
+			{"winner side", exportColumnTypeInt},
+			{"overtime count", exportColumnTypeInt},
+			{"max rounds", exportColumnTypeInt},
+			{"has vac live ban", exportColumnTypeBool},
+			{"content hash", exportColumnTypeString},
+		},
+		[]*Match{match},
+		func(match *Match) []any {
+			return []any{
+				match.Checksum,
+				match.Game.String(),
+				match.DemoFilePath,
+				match.DemoFileName,
+				match.Date,
+				match.Source.String(),
+				match.Type.String(),
+				match.ShareCode,
+				match.MapName,
+				match.ServerName,
+				match.ClientName,
+				match.TickCount,
+				match.TickRate,
+				match.FrameRate,
+				match.GameType.String(),
+				match.GameMode.String(),
+				match.GameModeStr().String(),
+				match.IsRanked,
+				match.Duration.Seconds(),
+				match.NetworkProtocol,
+				match.BuildNumber,
+				match.KillCount(),
+				match.AssistCount(),
+				match.DeathCount(),
+				match.ShotCount(),
+				winnerName,
+				int(winnerSide),
+				match.OvertimeCount,
+				match.MaxRounds,
+				match.HasVacLiveBan,
+				match.ContentHash,
+			}
+		},
+	)
+}
+
+func buildTeamsTable(match *Match) exportTable {
+	return newExportTable(
+		"teams",
+		[]exportColumn{
+			{"name", exportColumnTypeString},
+			{"letter", exportColumnTypeEnum},
+			{"score", exportColumnTypeInt},
+			{"score first half", exportColumnTypeInt},
+			{"score second half", exportColumnTypeInt},
+			{"current side", exportColumnTypeInt},
+			{"match checksum", exportColumnTypeString},
+		},
+		[]*Team{match.TeamA, match.TeamB},
+		func(team *Team) []any {
+			return []any{
+				team.Name,
+				team.Letter.String(),
+				team.Score,
+				team.ScoreFirstHalf,
+				team.ScoreSecondHalf,
+				int(*team.CurrentSide),
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildPlayersTable(match *Match) exportTable {
+	return newExportTable(
+		"players",
+		[]exportColumn{
+			{"name", exportColumnTypeString},
+			{"steamid", exportColumnTypeUint64},
+			{"score", exportColumnTypeInt},
+			{"team name", exportColumnTypeString},
+			{"kills", exportColumnTypeInt},
+			{"assists", exportColumnTypeInt},
+			{"deaths", exportColumnTypeInt},
+			{"headshots", exportColumnTypeInt},
+			{"hs %", exportColumnTypeInt},
+			{"k/d", exportColumnTypeFloat32},
+			{"kast", exportColumnTypeFloat32},
+			{"avg damages per round", exportColumnTypeFloat32},
+			{"avg kills per round", exportColumnTypeFloat32},
+			{"avg death per round", exportColumnTypeFloat32},
+			{"utility_damage_per_round", exportColumnTypeFloat32},
+			{"mvp", exportColumnTypeInt},
+			{"rank type", exportColumnTypeInt},
+			{"rank", exportColumnTypeInt},
+			{"old rank", exportColumnTypeInt},
+			{"win count", exportColumnTypeInt},
+			{"bomb planted", exportColumnTypeInt},
+			{"bomb defused", exportColumnTypeInt},
+			{"hostage rescued", exportColumnTypeInt},
+			{"health damage", exportColumnTypeInt},
+			{"armor damage", exportColumnTypeInt},
+			{"utility damage", exportColumnTypeInt},
+			{"1v1", exportColumnTypeInt},
+			{"1v2", exportColumnTypeInt},
+			{"1v3", exportColumnTypeInt},
+			{"1v4", exportColumnTypeInt},
+			{"1v5", exportColumnTypeInt},
+			{"1v1 won", exportColumnTypeInt},
+			{"1v2 won", exportColumnTypeInt},
+			{"1v3 won", exportColumnTypeInt},
+			{"1v4 won", exportColumnTypeInt},
+			{"1v5 won", exportColumnTypeInt},
+			{"1v1 lost", exportColumnTypeInt},
+			{"1v2 lost", exportColumnTypeInt},
+			{"1v3 lost", exportColumnTypeInt},
+			{"1v4 lost", exportColumnTypeInt},
+			{"1v5 lost", exportColumnTypeInt},
+			{"first kill", exportColumnTypeInt},
+			{"first death", exportColumnTypeInt},
+			{"trade kill", exportColumnTypeInt},
+			{"trade death", exportColumnTypeInt},
+			{"first trade kill", exportColumnTypeInt},
+			{"first trade death", exportColumnTypeInt},
+			{"1k", exportColumnTypeInt},
+			{"2k", exportColumnTypeInt},
+			{"3k", exportColumnTypeInt},
+			{"4k", exportColumnTypeInt},
+			{"5k", exportColumnTypeInt},
+			{"htlv 2", exportColumnTypeFloat32},
+			{"htlv", exportColumnTypeFloat32},
+			{"crosshair share code", exportColumnTypeString},
+			{"color", exportColumnTypeInt},
+			{"inspect weapon count", exportColumnTypeInt},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.Players(),
+		func(player *Player) []any {
+			return []any{
+				player.Name,
+				player.SteamID64,
+				player.Score,
+				player.TeamName(),
+				player.KillCount(),
+				player.AssistCount(),
+				player.DeathCount(),
+				player.HeadshotCount(),
+				player.HeadshotPercent(),
+				player.KillDeathRatio(),
+				player.KAST(),
+				player.AverageDamagePerRound(),
+				player.AverageKillPerRound(),
+				player.AverageDeathPerRound(),
+				player.UtilityDamagePerRound(),
+				player.MvpCount,
+				player.RankType,
+				player.Rank,
+				player.OldRank,
+				player.WinCount,
+				player.BombPlantedCount(),
+				player.BombDefusedCount(),
+				player.HostageRescuedCount(),
+				player.HealthDamage(),
+				player.ArmorDamage(),
+				player.UtilityDamage(),
+				player.OneVsOneCount(),
+				player.OneVsTwoCount(),
+				player.OneVsThreeCount(),
+				player.OneVsFourCount(),
+				player.OneVsFiveCount(),
+				player.OneVsOneWonCount(),
+				player.OneVsTwoWonCount(),
+				player.OneVsThreeWonCount(),
+				player.OneVsFourWonCount(),
+				player.OneVsFiveWonCount(),
+				player.OneVsOneLostCount(),
+				player.OneVsTwoLostCount(),
+				player.OneVsThreeLostCount(),
+				player.OneVsFourLostCount(),
+				player.OneVsFiveLostCount(),
+				player.FirstKillCount(),
+				player.FirstDeathCount(),
+				player.TradeKillCount(),
+				player.TradeDeathCount(),
+				player.FirstTradeKillCount(),
+				player.FirstTradeDeathCount(),
+				player.OneKillCount(),
+				player.TwoKillCount(),
+				player.ThreeKillCount(),
+				player.FourKillCount(),
+				player.FiveKillCount(),
+				player.HltvRating2(),
+				player.HltvRating(),
+				player.CrosshairShareCode,
+				int(player.Color),
+				player.InspectWeaponCount,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildPlayerPositionsTable(match *Match) exportTable {
+	return newExportTable(
+		"positions",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"is alive", exportColumnTypeBool},
+			{"x", exportColumnTypeFloat64},
+			{"y", exportColumnTypeFloat64},
+			{"z", exportColumnTypeFloat64},
+			{"yaw", exportColumnTypeFloat32},
+			{"pitch", exportColumnTypeFloat32},
+			{"flash duration remaining", exportColumnTypeFloat64},
+			{"side", exportColumnTypeInt},
+			{"money", exportColumnTypeInt},
+			{"health", exportColumnTypeInt},
+			{"armor", exportColumnTypeInt},
+			{"has helmet", exportColumnTypeBool},
+			{"has bomb", exportColumnTypeBool},
+			{"has defuse kit", exportColumnTypeBool},
+			{"is ducking", exportColumnTypeBool},
+			{"is airborne", exportColumnTypeBool},
+			{"is scoping", exportColumnTypeBool},
+			{"is defusing", exportColumnTypeBool},
+			{"is planting", exportColumnTypeBool},
+			{"is grabbing hostage", exportColumnTypeBool},
+			{"active weapon name", exportColumnTypeEnum},
+			{"equipments", exportColumnTypeString},
+			{"grenades", exportColumnTypeString},
+			{"pistols", exportColumnTypeString},
+			{"smgs", exportColumnTypeString},
+			{"rifles", exportColumnTypeString},
+			{"heavy", exportColumnTypeString},
+			{"steamid", exportColumnTypeUint64},
+			{"name", exportColumnTypeString},
+			{"round", exportColumnTypeInt},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.PlayerPositions,
+		func(position *PlayerPosition) []any {
+			return []any{
+				position.Frame,
+				position.Tick,
+				position.IsAlive,
+				position.X,
+				position.Y,
+				position.Z,
+				position.Yaw,
+				position.Pitch,
+				position.FlashDurationRemaining,
+				int(position.Side),
+				position.Money,
+				position.Health,
+				position.Armor,
+				position.HasHelmet,
+				position.HasBomb,
+				position.HasDefuseKit,
+				position.IsDucking,
+				position.IsAirborne,
+				position.IsScoping,
+				position.IsDefusing,
+				position.IsPlanting,
+				position.IsGrabbingHostage,
+				position.ActiveWeaponName.String(),
+				strings.Join(slice.ToStrings(position.Equipments), ","),
+				strings.Join(slice.ToStrings(position.Grenades), ","),
+				strings.Join(slice.ToStrings(position.Pistols), ","),
+				strings.Join(slice.ToStrings(position.SMGs), ","),
+				strings.Join(slice.ToStrings(position.Rifles), ","),
+				strings.Join(slice.ToStrings(position.Heavy), ","),
+				position.SteamID64,
+				position.Name,
+				position.RoundNumber,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildShotsTable(match *Match) exportTable {
+	return newExportTable(
+		"shots",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"weapon name", exportColumnTypeEnum},
+			{"weapon id", exportColumnTypeString},
+			{"projectile id", exportColumnTypeInt},
+			{"x", exportColumnTypeFloat64},
+			{"y", exportColumnTypeFloat64},
+			{"z", exportColumnTypeFloat64},
+			{"player name", exportColumnTypeString},
+			{"player steamid", exportColumnTypeUint64},
+			{"player team name", exportColumnTypeString},
+			{"player side", exportColumnTypeInt},
+			{"is player controlling bot", exportColumnTypeBool},
+			{"player velocity x", exportColumnTypeFloat64},
+			{"player velocity y", exportColumnTypeFloat64},
+			{"player velocity z", exportColumnTypeFloat64},
+			{"yaw", exportColumnTypeFloat32},
+			{"pitch", exportColumnTypeFloat32},
+			{"recoil index", exportColumnTypeFloat32},
+			{"aim punch angle x", exportColumnTypeFloat64},
+			{"aim punch angle y", exportColumnTypeFloat64},
+			{"view punch angle x", exportColumnTypeFloat64},
+			{"view punch angle y", exportColumnTypeFloat64},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.Shots,
+		func(shot *Shot) []any {
+			return []any{
+				shot.Frame,
+				shot.Tick,
+				shot.RoundNumber,
+				shot.WeaponName.String(),
+				shot.WeaponID,
+				shot.ProjectileID,
+				shot.X,
+				shot.Y,
+				shot.Z,
+				shot.PlayerName,
+				shot.PlayerSteamID64,
+				shot.PlayerTeamName,
+				int(shot.PlayerSide),
+				shot.IsPlayerControllingBot,
+				shot.PlayerVelocityX,
+				shot.PlayerVelocityY,
+				shot.PlayerVelocityZ,
+				shot.Yaw,
+				shot.Pitch,
+				shot.RecoilIndex,
+				shot.AimPunchAngleX,
+				shot.AimPunchAngleY,
+				shot.ViewPunchAngleX,
+				shot.ViewPunchAngleY,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildRoundsTable(match *Match) exportTable {
+	return newExportTable(
+		"rounds",
+		[]exportColumn{
+			{"number", exportColumnTypeInt},
+			{"start tick", exportColumnTypeInt},
+			{"start frame", exportColumnTypeInt},
+			{"freezetime end tick", exportColumnTypeInt},
+			{"freezetime end frame", exportColumnTypeInt},
+			{"end tick", exportColumnTypeInt},
+			{"end frame", exportColumnTypeInt},
+			{"end officially tick", exportColumnTypeInt},
+			{"end officially frame", exportColumnTypeInt},
+			{"team a name", exportColumnTypeString},
+			{"team b name", exportColumnTypeString},
+			{"score team a", exportColumnTypeInt},
+			{"score team b", exportColumnTypeInt},
+			{"team a side", exportColumnTypeInt},
+			{"team b side", exportColumnTypeInt},
+			{"team a start money", exportColumnTypeInt},
+			{"team b start money", exportColumnTypeInt},
+			{"team a equipment value", exportColumnTypeInt},
+			{"team b equipment value", exportColumnTypeInt},
+			{"team a money spent", exportColumnTypeInt},
+			{"team b money spent", exportColumnTypeInt},
+			{"team a economy type", exportColumnTypeEnum},
+			{"team b economy type", exportColumnTypeEnum},
+			{"duration", exportColumnTypeInt},
+			{"end reason", exportColumnTypeInt},
+			{"winner name", exportColumnTypeString},
+			{"winner side", exportColumnTypeInt},
+			{"overtime number", exportColumnTypeInt},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.Rounds,
+		func(round *Round) []any {
+			return []any{
+				round.Number,
+				round.StartTick,
+				round.StartFrame,
+				round.FreezeTimeEndTick,
+				round.FreezeTimeEndFrame,
+				round.EndTick,
+				round.EndFrame,
+				round.EndOfficiallyTick,
+				round.EndOfficiallyFrame,
+				round.TeamAName,
+				round.TeamBName,
+				round.TeamAScore,
+				round.TeamBScore,
+				int(round.TeamASide),
+				int(round.TeamBSide),
+				round.StartMoneyTeamA(),
+				round.StartMoneyTeamB(),
+				round.TeamAEquipmentValue,
+				round.TeamBEquipmentValue,
+				round.TeamAMoneySpent,
+				round.TeamBMoneySpent,
+				round.TeamAEconomyType.String(),
+				round.TeamBEconomyType.String(),
+				round.Duration,
+				int(round.EndReason),
+				round.WinnerName,
+				int(round.WinnerSide),
+				round.OvertimeNumber,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildRoundEconomiesTable(match *Match) exportTable {
+	return newExportTable(
+		"players_economy",
+		[]exportColumn{
+			{"steamid", exportColumnTypeUint64},
+			{"name", exportColumnTypeString},
+			{"player side", exportColumnTypeInt},
+			{"start money", exportColumnTypeInt},
+			{"money spent", exportColumnTypeInt},
+			{"equipment value", exportColumnTypeInt},
+			{"type", exportColumnTypeEnum},
+			{"round", exportColumnTypeInt},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.PlayerEconomies,
+		func(economy *PlayerEconomy) []any {
+			return []any{
+				economy.SteamID64,
+				economy.Name,
+				int(economy.PlayerSide),
+				economy.StartMoney,
+				economy.MoneySpent,
+				economy.EquipmentValue,
+				economy.Type.String(),
+				economy.RoundNumber,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildClutchesTable(match *Match) exportTable {
+	return newExportTable(
+		"clutches",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"opponents", exportColumnTypeInt},
+			{"side", exportColumnTypeInt},
+			{"won", exportColumnTypeBool},
+			{"steamid", exportColumnTypeUint64},
+			{"name", exportColumnTypeString},
+			{"survived", exportColumnTypeBool},
+			{"kill_count", exportColumnTypeInt},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.Clutches,
+		func(clutch *Clutch) []any {
+			return []any{
+				clutch.Frame,
+				clutch.Tick,
+				clutch.RoundNumber,
+				clutch.OpponentCount,
+				int(clutch.Side),
+				clutch.HasWon,
+				clutch.ClutcherSteamID64,
+				clutch.ClutcherName,
+				clutch.ClutcherSurvived,
+				clutch.ClutcherKillCount,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildChickenDeathsTable(match *Match) exportTable {
+	return newExportTable(
+		"chicken_deaths",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"killer steam id", exportColumnTypeUint64},
+			{"weapon name", exportColumnTypeEnum},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.ChickenDeaths,
+		func(chickenDeath *ChickenDeath) []any {
+			return []any{
+				chickenDeath.Frame,
+				chickenDeath.Tick,
+				chickenDeath.RoundNumber,
+				chickenDeath.KillerSteamID,
+				chickenDeath.WeaponName.String(),
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildChickenPositionsTable(match *Match) exportTable {
+	return newExportTable(
+		"chicken_positions",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"x", exportColumnTypeFloat64},
+			{"y", exportColumnTypeFloat64},
+			{"z", exportColumnTypeFloat64},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.ChickenPositions,
+		func(position *ChickenPosition) []any {
+			return []any{
+				position.Frame,
+				position.Tick,
+				position.RoundNumber,
+				position.X,
+				position.Y,
+				position.Z,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildDamagesTable(match *Match) exportTable {
+	return newExportTable(
+		"damages",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"health", exportColumnTypeInt},
+			{"armor", exportColumnTypeInt},
+			{"victim health", exportColumnTypeInt},
+			{"victim new health", exportColumnTypeInt},
+			{"victim armor", exportColumnTypeInt},
+			{"victim new armor", exportColumnTypeInt},
+			{"attacker steamid", exportColumnTypeUint64},
+			{"attacker side", exportColumnTypeInt},
+			{"attacker team name", exportColumnTypeString},
+			{"is attacker controlling bot", exportColumnTypeBool},
+			{"victim steamid", exportColumnTypeUint64},
+			{"victim side", exportColumnTypeInt},
+			{"victim team name", exportColumnTypeString},
+			{"is victim controlling bot", exportColumnTypeBool},
+			{"weapon name", exportColumnTypeEnum},
+			{"weapon class", exportColumnTypeEnum},
+			{"hitgroup", exportColumnTypeInt},
+			{"weapon unique id", exportColumnTypeString},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.Damages,
+		func(damage *Damage) []any {
+			return []any{
+				damage.Frame,
+				damage.Tick,
+				damage.RoundNumber,
+				damage.HealthDamage,
+				damage.ArmorDamage,
+				damage.VictimHealth,
+				damage.VictimNewHealth,
+				damage.VictimArmor,
+				damage.VictimNewArmor,
+				damage.AttackerSteamID64,
+				int(damage.AttackerSide),
+				damage.AttackerTeamName,
+				damage.IsAttackerControllingBot,
+				damage.VictimSteamID64,
+				int(damage.VictimSide),
+				damage.VictimTeamName,
+				damage.IsVictimControllingBot,
+				damage.WeaponName.String(),
+				string(damage.WeaponType),
+				int(damage.HitGroup),
+				damage.WeaponUniqueID,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildKillsTable(match *Match) exportTable {
+	return newExportTable(
+		"kills",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"killer name", exportColumnTypeString},
+			{"killer steamid", exportColumnTypeUint64},
+			{"killer side", exportColumnTypeInt},
+			{"killer team name", exportColumnTypeString},
+			{"victim name", exportColumnTypeString},
+			{"victim steamid", exportColumnTypeUint64},
+			{"victim side", exportColumnTypeInt},
+			{"victim team name", exportColumnTypeString},
+			{"assister name", exportColumnTypeString},
+			{"assister steamid", exportColumnTypeUint64},
+			{"assister side", exportColumnTypeInt},
+			{"assister team name", exportColumnTypeString},
+			{"weapon name", exportColumnTypeEnum},
+			{"weapon_type", exportColumnTypeEnum},
+			{"headshot", exportColumnTypeBool},
+			{"penetrated objects", exportColumnTypeInt},
+			{"is flash assist", exportColumnTypeBool},
+			{"killer controlling bot", exportColumnTypeBool},
+			{"victim controlling bot", exportColumnTypeBool},
+			{"assister controlling bot", exportColumnTypeBool},
+			{"killer x", exportColumnTypeFloat64},
+			{"killer y", exportColumnTypeFloat64},
+			{"killer z", exportColumnTypeFloat64},
+			{"is killer airborne", exportColumnTypeBool},
+			{"is killer blinded", exportColumnTypeBool},
+			{"victim x", exportColumnTypeFloat64},
+			{"victim y", exportColumnTypeFloat64},
+			{"victim z", exportColumnTypeFloat64},
+			{"is victim airborne", exportColumnTypeBool},
+			{"is victim blinded", exportColumnTypeBool},
+			{"is victim inspecting weapon", exportColumnTypeBool},
+			{"assister x", exportColumnTypeFloat64},
+			{"assister y", exportColumnTypeFloat64},
+			{"assister z", exportColumnTypeFloat64},
+			{"is trade kill", exportColumnTypeBool},
+			{"is trade death", exportColumnTypeBool},
+			{"is through smoke", exportColumnTypeBool},
+			{"is no scope", exportColumnTypeBool},
+			{"distance", exportColumnTypeFloat32},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.Kills,
+		func(kill *Kill) []any {
+			return []any{
+				kill.Frame,
+				kill.Tick,
+				kill.RoundNumber,
+				kill.KillerName,
+				kill.KillerSteamID64,
+				int(kill.KillerSide),
+				kill.KillerTeamName,
+				kill.VictimName,
+				kill.VictimSteamID64,
+				int(kill.VictimSide),
+				kill.VictimTeamName,
+				kill.AssisterName,
+				kill.AssisterSteamID64,
+				int(kill.AssisterSide),
+				kill.AssisterTeamName,
+				kill.WeaponName.String(),
+				string(kill.WeaponType),
+				kill.IsHeadshot,
+				kill.PenetratedObjects,
+				kill.IsAssistedFlash,
+				kill.IsKillerControllingBot,
+				kill.IsVictimControllingBot,
+				kill.IsAssisterControllingBot,
+				kill.KillerX,
+				kill.KillerY,
+				kill.KillerZ,
+				kill.IsKillerAirborne,
+				kill.IsKillerBlinded,
+				kill.VictimX,
+				kill.VictimY,
+				kill.VictimZ,
+				kill.IsVictimAirborne,
+				kill.IsVictimBlinded,
+				kill.IsVictimInspectingWeapon,
+				kill.AssisterX,
+				kill.AssisterY,
+				kill.AssisterZ,
+				kill.IsTradeKill,
+				kill.IsTradeDeath,
+				kill.IsThroughSmoke,
+				kill.IsNoScope,
+				kill.Distance,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildBombsPlantedTable(match *Match) exportTable {
+	return newExportTable(
+		"bombs_planted",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"site", exportColumnTypeEnum},
+			{"planter steamid", exportColumnTypeUint64},
+			{"planter name", exportColumnTypeString},
+			{"is player controlling bot", exportColumnTypeBool},
+			{"x", exportColumnTypeFloat64},
+			{"y", exportColumnTypeFloat64},
+			{"z", exportColumnTypeFloat64},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.BombsPlanted,
+		func(bombPlanted *BombPlanted) []any {
+			return []any{
+				bombPlanted.Frame,
+				bombPlanted.Tick,
+				bombPlanted.RoundNumber,
+				bombPlanted.Site,
+				bombPlanted.PlanterSteamID64,
+				bombPlanted.PlanterName,
+				bombPlanted.IsPlayerControllingBot,
+				bombPlanted.X,
+				bombPlanted.Y,
+				bombPlanted.Z,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildBombsDefuseStartTable(match *Match) exportTable {
+	return newExportTable(
+		"bombs_defuse_start",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"defuser steamid", exportColumnTypeUint64},
+			{"defuser name", exportColumnTypeString},
+			{"is defuser controlling bot", exportColumnTypeBool},
+			{"x", exportColumnTypeFloat64},
+			{"y", exportColumnTypeFloat64},
+			{"z", exportColumnTypeFloat64},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.BombsDefuseStart,
+		func(bombDefuseStart *BombDefuseStart) []any {
+			return []any{
+				bombDefuseStart.Frame,
+				bombDefuseStart.Tick,
+				bombDefuseStart.RoundNumber,
+				bombDefuseStart.PlanterSteamID64,
+				bombDefuseStart.PlanterName,
+				bombDefuseStart.IsPlayerControllingBot,
+				bombDefuseStart.X,
+				bombDefuseStart.Y,
+				bombDefuseStart.Z,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildBombsDefusedTable(match *Match) exportTable {
+	return newExportTable(
+		"bombs_defused",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"site", exportColumnTypeEnum},
+			{"defuser steamid", exportColumnTypeUint64},
+			{"defuser name", exportColumnTypeString},
+			{"is player controlling bot", exportColumnTypeBool},
+			{"x", exportColumnTypeFloat64},
+			{"y", exportColumnTypeFloat64},
+			{"z", exportColumnTypeFloat64},
+			{"counter terrorist alive count", exportColumnTypeInt},
+			{"terrorist alive count", exportColumnTypeInt},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.BombsDefused,
+		func(bombDefused *BombDefused) []any {
+			return []any{
+				bombDefused.Frame,
+				bombDefused.Tick,
+				bombDefused.RoundNumber,
+				bombDefused.Site,
+				bombDefused.DefuserSteamID64,
+				bombDefused.DefuserName,
+				bombDefused.IsPlayerControllingBot,
+				bombDefused.X,
+				bombDefused.Y,
+				bombDefused.Z,
+				bombDefused.CounterTerroristAliveCount,
+				bombDefused.TerroristAliveCount,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildBombsExplodedTable(match *Match) exportTable {
+	return newExportTable(
+		"bombs_exploded",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"site", exportColumnTypeEnum},
+			{"planter steamid", exportColumnTypeUint64},
+			{"planter name", exportColumnTypeString},
+			{"is player controlling bot", exportColumnTypeBool},
+			{"x", exportColumnTypeFloat64},
+			{"y", exportColumnTypeFloat64},
+			{"z", exportColumnTypeFloat64},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.BombsExploded,
+		func(bombExploded *BombExploded) []any {
+			return []any{
+				bombExploded.Frame,
+				bombExploded.Tick,
+				bombExploded.RoundNumber,
+				bombExploded.Site,
+				bombExploded.PlanterSteamID64,
+				bombExploded.PlanterName,
+				bombExploded.IsPlayerControllingBot,
+				bombExploded.X,
+				bombExploded.Y,
+				bombExploded.Z,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildBombsPlantStartTable(match *Match) exportTable {
+	return newExportTable(
+		"bombs_plant_start",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"site", exportColumnTypeEnum},
+			{"planter steamid", exportColumnTypeUint64},
+			{"planter name", exportColumnTypeString},
+			{"is player controlling bot", exportColumnTypeBool},
+			{"x", exportColumnTypeFloat64},
+			{"y", exportColumnTypeFloat64},
+			{"z", exportColumnTypeFloat64},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.BombsPlantStart,
+		func(bombPlantStart *BombPlantStart) []any {
+			return []any{
+				bombPlantStart.Frame,
+				bombPlantStart.Tick,
+				bombPlantStart.RoundNumber,
+				bombPlantStart.Site,
+				bombPlantStart.PlanterSteamID64,
+				bombPlantStart.PlanterName,
+				bombPlantStart.IsPlayerControllingBot,
+				bombPlantStart.X,
+				bombPlantStart.Y,
+				bombPlantStart.Z,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildPlayersFlashedTable(match *Match) exportTable {
+	return newExportTable(
+		"players_flashed",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"duration", exportColumnTypeFloat32},
+			{"flashed steamid", exportColumnTypeUint64},
+			{"flashed name", exportColumnTypeString},
+			{"flashed side", exportColumnTypeInt},
+			{"is flashed controlling bot", exportColumnTypeBool},
+			{"flasher steamid", exportColumnTypeUint64},
+			{"flasher name", exportColumnTypeString},
+			{"flasher side", exportColumnTypeInt},
+			{"is flasher controlling bot", exportColumnTypeBool},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.PlayersFlashed,
+		func(playerFlashed *PlayerFlashed) []any {
+			return []any{
+				playerFlashed.Frame,
+				playerFlashed.Tick,
+				playerFlashed.RoundNumber,
+				playerFlashed.Duration,
+				playerFlashed.FlashedSteamID64,
+				playerFlashed.FlashedName,
+				int(playerFlashed.FlashedSide),
+				playerFlashed.IsFlashedControllingBot,
+				playerFlashed.FlasherSteamID64,
+				playerFlashed.FlasherName,
+				int(playerFlashed.FlasherSide),
+				playerFlashed.IsFlasherControllingBot,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildPlayersBuyTable(match *Match) exportTable {
+	return newExportTable(
+		"players_buy",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"player steamid", exportColumnTypeUint64},
+			{"player side", exportColumnTypeInt},
+			{"player name", exportColumnTypeString},
+			{"weapon name", exportColumnTypeEnum},
+			{"weapon type", exportColumnTypeEnum},
+			{"weapon unique id", exportColumnTypeString},
+			{"has refunded", exportColumnTypeBool},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.PlayersBuy,
+		func(playerBuy *PlayerBuy) []any {
+			return []any{
+				playerBuy.Frame,
+				playerBuy.Tick,
+				playerBuy.RoundNumber,
+				playerBuy.PlayerSteamID64,
+				int(playerBuy.PlayerSide),
+				playerBuy.PlayerName,
+				playerBuy.WeaponName.String(),
+				playerBuy.WeaponType.String(),
+				playerBuy.WeaponUniqueID,
+				playerBuy.HasRefunded,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildGrenadePositionsTable(match *Match) exportTable {
+	return newExportTable(
+		"grenade_positions",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"grenade id", exportColumnTypeString},
+			{"projectile id", exportColumnTypeInt},
+			{"grenade name", exportColumnTypeEnum},
+			{"x", exportColumnTypeFloat64},
+			{"y", exportColumnTypeFloat64},
+			{"z", exportColumnTypeFloat64},
+			{"thrower steamid", exportColumnTypeUint64},
+			{"thrower name", exportColumnTypeString},
+			{"thrower side", exportColumnTypeInt},
+			{"thrower team name", exportColumnTypeString},
+			{"thrower velocity x", exportColumnTypeFloat64},
+			{"thrower velocity y", exportColumnTypeFloat64},
+			{"thrower velocity z", exportColumnTypeFloat64},
+			{"thrower yaw", exportColumnTypeFloat32},
+			{"thrower pitch", exportColumnTypeFloat32},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.GrenadePositions,
+		func(position *GrenadePosition) []any {
+			return []any{
+				position.Frame,
+				position.Tick,
+				position.RoundNumber,
+				position.GrenadeID,
+				position.ProjectileID,
+				position.GrenadeName.String(),
+				position.X,
+				position.Y,
+				position.Z,
+				position.ThrowerSteamID64,
+				position.ThrowerName,
+				int(position.ThrowerSide),
+				position.ThrowerTeamName,
+				position.ThrowerVelocityX,
+				position.ThrowerVelocityY,
+				position.ThrowerVelocityZ,
+				position.ThrowerYaw,
+				position.ThrowerPitch,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildGrenadeBouncesTable(match *Match) exportTable {
+	return newExportTable(
+		"grenade_bounces",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"grenade id", exportColumnTypeString},
+			{"projectile id", exportColumnTypeInt},
+			{"grenade name", exportColumnTypeEnum},
+			{"x", exportColumnTypeFloat64},
+			{"y", exportColumnTypeFloat64},
+			{"z", exportColumnTypeFloat64},
+			{"thrower steamid", exportColumnTypeUint64},
+			{"thrower name", exportColumnTypeString},
+			{"thrower side", exportColumnTypeInt},
+			{"thrower team name", exportColumnTypeString},
+			{"thrower velocity x", exportColumnTypeFloat64},
+			{"thrower velocity y", exportColumnTypeFloat64},
+			{"thrower velocity z", exportColumnTypeFloat64},
+			{"thrower yaw", exportColumnTypeFloat32},
+			{"thrower pitch", exportColumnTypeFloat32},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.GrenadeBounces,
+		func(position *GrenadeBounce) []any {
+			return []any{
+				position.Frame,
+				position.Tick,
+				position.RoundNumber,
+				position.GrenadeID,
+				position.ProjectileID,
+				position.GrenadeName.String(),
+				position.X,
+				position.Y,
+				position.Z,
+				position.ThrowerSteamID64,
+				position.ThrowerName,
+				int(position.ThrowerSide),
+				position.ThrowerTeamName,
+				position.ThrowerVelocityX,
+				position.ThrowerVelocityY,
+				position.ThrowerVelocityZ,
+				position.ThrowerYaw,
+				position.ThrowerPitch,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildGrenadeProjectilesDestroyTable(match *Match) exportTable {
+	return newExportTable(
+		"grenade_projectiles_destroy",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"grenade id", exportColumnTypeString},
+			{"projectile id", exportColumnTypeInt},
+			{"grenade name", exportColumnTypeEnum},
+			{"x", exportColumnTypeFloat64},
+			{"y", exportColumnTypeFloat64},
+			{"z", exportColumnTypeFloat64},
+			{"thrower steamid", exportColumnTypeUint64},
+			{"thrower name", exportColumnTypeString},
+			{"thrower side", exportColumnTypeInt},
+			{"thrower team name", exportColumnTypeString},
+			{"thrower velocity x", exportColumnTypeFloat64},
+			{"thrower velocity y", exportColumnTypeFloat64},
+			{"thrower velocity z", exportColumnTypeFloat64},
+			{"thrower yaw", exportColumnTypeFloat32},
+			{"thrower pitch", exportColumnTypeFloat32},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.GrenadeProjectilesDestroy,
+		func(event *GrenadeProjectileDestroy) []any {
+			return []any{
+				event.Frame,
+				event.Tick,
+				event.RoundNumber,
+				event.GrenadeID,
+				event.ProjectileID,
+				event.GrenadeName.String(),
+				event.X,
+				event.Y,
+				event.Z,
+				event.ThrowerSteamID64,
+				event.ThrowerName,
+				int(event.ThrowerSide),
+				event.ThrowerTeamName,
+				event.ThrowerVelocityX,
+				event.ThrowerVelocityY,
+				event.ThrowerVelocityZ,
+				event.ThrowerYaw,
+				event.ThrowerPitch,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildSmokesStartTable(match *Match) exportTable {
+	return newExportTable(
+		"smokes_start",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"grenade id", exportColumnTypeString},
+			{"projectile id", exportColumnTypeInt},
+			{"x", exportColumnTypeFloat64},
+			{"y", exportColumnTypeFloat64},
+			{"z", exportColumnTypeFloat64},
+			{"thrower steamid", exportColumnTypeUint64},
+			{"thrower name", exportColumnTypeString},
+			{"thrower side", exportColumnTypeInt},
+			{"thrower team name", exportColumnTypeString},
+			{"thrower velocity x", exportColumnTypeFloat64},
+			{"thrower velocity y", exportColumnTypeFloat64},
+			{"thrower velocity z", exportColumnTypeFloat64},
+			{"thrower yaw", exportColumnTypeFloat32},
+			{"thrower pitch", exportColumnTypeFloat32},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.SmokesStart,
+		func(event *SmokeStart) []any {
+			return []any{
+				event.Frame,
+				event.Tick,
+				event.RoundNumber,
+				event.GrenadeID,
+				event.ProjectileID,
+				event.X,
+				event.Y,
+				event.Z,
+				event.ThrowerSteamID64,
+				event.ThrowerName,
+				int(event.ThrowerSide),
+				event.ThrowerTeamName,
+				event.ThrowerVelocityX,
+				event.ThrowerVelocityY,
+				event.ThrowerVelocityZ,
+				event.ThrowerYaw,
+				event.ThrowerPitch,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildHeGrenadesExplodeTable(match *Match) exportTable {
+	return newExportTable(
+		"he_grenades_explode",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"grenade id", exportColumnTypeString},
+			{"projectile id", exportColumnTypeInt},
+			{"x", exportColumnTypeFloat64},
+			{"y", exportColumnTypeFloat64},
+			{"z", exportColumnTypeFloat64},
+			{"thrower steamid", exportColumnTypeUint64},
+			{"thrower name", exportColumnTypeString},
+			{"thrower side", exportColumnTypeInt},
+			{"thrower team name", exportColumnTypeString},
+			{"thrower velocity x", exportColumnTypeFloat64},
+			{"thrower velocity y", exportColumnTypeFloat64},
+			{"thrower velocity z", exportColumnTypeFloat64},
+			{"thrower yaw", exportColumnTypeFloat32},
+			{"thrower pitch", exportColumnTypeFloat32},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.HeGrenadesExplode,
+		func(event *HeGrenadeExplode) []any {
+			return []any{
+				event.Frame,
+				event.Tick,
+				event.RoundNumber,
+				event.GrenadeID,
+				event.ProjectileID,
+				event.X,
+				event.Y,
+				event.Z,
+				event.ThrowerSteamID64,
+				event.ThrowerName,
+				int(event.ThrowerSide),
+				event.ThrowerTeamName,
+				event.ThrowerVelocityX,
+				event.ThrowerVelocityY,
+				event.ThrowerVelocityZ,
+				event.ThrowerYaw,
+				event.ThrowerPitch,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildFlashbangsExplodeTable(match *Match) exportTable {
+	return newExportTable(
+		"flashbangs_explode",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"grenade id", exportColumnTypeString},
+			{"projectile id", exportColumnTypeInt},
+			{"x", exportColumnTypeFloat64},
+			{"y", exportColumnTypeFloat64},
+			{"z", exportColumnTypeFloat64},
+			{"thrower steamid", exportColumnTypeUint64},
+			{"thrower name", exportColumnTypeString},
+			{"thrower side", exportColumnTypeInt},
+			{"thrower team name", exportColumnTypeString},
+			{"thrower velocity x", exportColumnTypeFloat64},
+			{"thrower velocity y", exportColumnTypeFloat64},
+			{"thrower velocity z", exportColumnTypeFloat64},
+			{"thrower yaw", exportColumnTypeFloat32},
+			{"thrower pitch", exportColumnTypeFloat32},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.FlashbangsExplode,
+		func(event *FlashbangExplode) []any {
+			return []any{
+				event.Frame,
+				event.Tick,
+				event.RoundNumber,
+				event.GrenadeID,
+				event.ProjectileID,
+				event.X,
+				event.Y,
+				event.Z,
+				event.ThrowerSteamID64,
+				event.ThrowerName,
+				int(event.ThrowerSide),
+				event.ThrowerTeamName,
+				event.ThrowerVelocityX,
+				event.ThrowerVelocityY,
+				event.ThrowerVelocityZ,
+				event.ThrowerYaw,
+				event.ThrowerPitch,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildDecoysStartTable(match *Match) exportTable {
+	return newExportTable(
+		"decoys_start",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"grenade id", exportColumnTypeString},
+			{"projectile id", exportColumnTypeInt},
+			{"x", exportColumnTypeFloat64},
+			{"y", exportColumnTypeFloat64},
+			{"z", exportColumnTypeFloat64},
+			{"thrower steamid", exportColumnTypeUint64},
+			{"thrower name", exportColumnTypeString},
+			{"thrower side", exportColumnTypeInt},
+			{"thrower team name", exportColumnTypeString},
+			{"thrower velocity x", exportColumnTypeFloat64},
+			{"thrower velocity y", exportColumnTypeFloat64},
+			{"thrower velocity z", exportColumnTypeFloat64},
+			{"thrower yaw", exportColumnTypeFloat32},
+			{"thrower pitch", exportColumnTypeFloat32},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.DecoysStart,
+		func(event *DecoyStart) []any {
+			return []any{
+				event.Frame,
+				event.Tick,
+				event.RoundNumber,
+				event.GrenadeID,
+				event.ProjectileID,
+				event.X,
+				event.Y,
+				event.Z,
+				event.ThrowerSteamID64,
+				event.ThrowerName,
+				int(event.ThrowerSide),
+				event.ThrowerTeamName,
+				event.ThrowerVelocityX,
+				event.ThrowerVelocityY,
+				event.ThrowerVelocityZ,
+				event.ThrowerYaw,
+				event.ThrowerPitch,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildInfernoPositionsTable(match *Match) exportTable {
+	return newExportTable(
+		"inferno_positions",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"thrower steamid", exportColumnTypeUint64},
+			{"thrower name", exportColumnTypeString},
+			{"unique id", exportColumnTypeInt},
+			{"x", exportColumnTypeFloat64},
+			{"y", exportColumnTypeFloat64},
+			{"z", exportColumnTypeFloat64},
+			{"convex hull 2d", exportColumnTypeString},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.InfernoPositions,
+		func(position *InfernoPosition) []any {
+			var convexHull2D string
+			for index, point := range position.ConvexHull2D {
+				startCharacter := ""
+				if index > 0 {
+					startCharacter = ","
+				}
+				convexHull2D += fmt.Sprintf("%s%f,%f", startCharacter, point.X, point.Y)
+			}
+			if convexHull2D == "" {
+				convexHull2D = "0,0"
+			}
+
+			return []any{
+				position.Frame,
+				position.Tick,
+				position.RoundNumber,
+				position.ThrowerSteamID64,
+				position.ThrowerName,
+				position.UniqueID,
+				position.X,
+				position.Y,
+				position.Z,
+				convexHull2D,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildChatMessagesTable(match *Match) exportTable {
+	return newExportTable(
+		"chat_messages",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"steamid", exportColumnTypeUint64},
+			{"name", exportColumnTypeString},
+			{"message", exportColumnTypeString},
+			{"is alive", exportColumnTypeBool},
+			{"side", exportColumnTypeInt},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.ChatMessages,
+		func(chatMessage *ChatMessage) []any {
+			return []any{
+				chatMessage.Frame,
+				chatMessage.Tick,
+				chatMessage.RoundNumber,
+				chatMessage.SenderSteamID64,
+				chatMessage.SenderName,
+				chatMessage.Message,
+				chatMessage.IsSenderAlive,
+				int(chatMessage.SenderSide),
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildHostagePositionsTable(match *Match) exportTable {
+	return newExportTable(
+		"hostage_positions",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"x", exportColumnTypeFloat64},
+			{"y", exportColumnTypeFloat64},
+			{"z", exportColumnTypeFloat64},
+			{"state", exportColumnTypeInt},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.HostagePositions,
+		func(position *HostagePosition) []any {
+			return []any{
+				position.Frame,
+				position.Tick,
+				position.RoundNumber,
+				position.X,
+				position.Y,
+				position.Z,
+				int(position.State),
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildHostagePickUpStartTable(match *Match) exportTable {
+	return newExportTable(
+		"hostage_pick_up_start",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"steamid", exportColumnTypeUint64},
+			{"is player controlling bot", exportColumnTypeBool},
+			{"hostage entity id", exportColumnTypeInt},
+			{"x", exportColumnTypeFloat64},
+			{"y", exportColumnTypeFloat64},
+			{"z", exportColumnTypeFloat64},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.HostagePickUpStart,
+		func(event *HostagePickUpStart) []any {
+			return []any{
+				event.Frame,
+				event.Tick,
+				event.RoundNumber,
+				event.PlayerSteamID64,
+				event.IsPlayerControllingBot,
+				event.HostageEntityId,
+				event.X,
+				event.Y,
+				event.Z,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildHostagePickedUpTable(match *Match) exportTable {
+	return newExportTable(
+		"hostage_picked_up",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"steamid", exportColumnTypeUint64},
+			{"is player controlling bot", exportColumnTypeBool},
+			{"hostage entity id", exportColumnTypeInt},
+			{"x", exportColumnTypeFloat64},
+			{"y", exportColumnTypeFloat64},
+			{"z", exportColumnTypeFloat64},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.HostagePickedUp,
+		func(event *HostagePickedUp) []any {
+			return []any{
+				event.Frame,
+				event.Tick,
+				event.RoundNumber,
+				event.PlayerSteamID64,
+				event.IsPlayerControllingBot,
+				event.HostageEntityId,
+				event.X,
+				event.Y,
+				event.Z,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildHostageRescuedTable(match *Match) exportTable {
+	return newExportTable(
+		"hostage_rescued",
+		[]exportColumn{
+			{"frame", exportColumnTypeInt},
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"steamid", exportColumnTypeUint64},
+			{"is player controlling bot", exportColumnTypeBool},
+			{"hostage entity id", exportColumnTypeInt},
+			{"x", exportColumnTypeFloat64},
+			{"y", exportColumnTypeFloat64},
+			{"z", exportColumnTypeFloat64},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.HostageRescued,
+		func(event *HostageRescued) []any {
+			return []any{
+				event.Frame,
+				event.Tick,
+				event.RoundNumber,
+				event.PlayerSteamID64,
+				event.IsPlayerControllingBot,
+				event.HostageEntityId,
+				event.X,
+				event.Y,
+				event.Z,
+				match.Checksum,
+			}
+		},
+	)
+}
+
+func buildDiagnosticsTable(match *Match) exportTable {
+	return newExportTable(
+		"diagnostics",
+		[]exportColumn{
+			{"tick", exportColumnTypeInt},
+			{"round", exportColumnTypeInt},
+			{"severity", exportColumnTypeEnum},
+			{"code", exportColumnTypeEnum},
+			{"message", exportColumnTypeString},
+			{"match checksum", exportColumnTypeString},
+		},
+		match.Diagnostics,
+		func(diagnostic *Diagnostic) []any {
+			return []any{
+				diagnostic.Tick,
+				diagnostic.RoundNumber,
+				diagnostic.Severity.String(),
+				diagnostic.Code.String(),
+				diagnostic.Message,
+				match.Checksum,
+			}
+		},
+	)
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/akiver/cs-demo-analyzer/internal/demo"
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+)
+
+// newMatchWithOneOfEachEvent returns a match that contains one zero value of each event.
+func newMatchWithOneOfEachEvent() *Match {
+	match := newMatch(constants.DemoSourceValve, &demo.Demo{})
+	value := reflect.ValueOf(&match).Elem()
+	for index := range value.NumField() {
+		field := value.Field(index)
+		if field.Kind() != reflect.Slice || !field.CanSet() || field.Type().Elem().Kind() != reflect.Pointer {
+			continue
+		}
+		field.Set(reflect.Append(field, reflect.New(field.Type().Elem().Elem())))
+	}
+	match.PlayersBySteamID[1] = &Player{match: &match, SteamID64: 1, Team: match.TeamA}
+	match.Rounds[0].analyzer = &Analyzer{match: &match}
+
+	return &match
+}
+
+func TestExportTablesValuesMatchColumns(t *testing.T) {
+	match := newMatchWithOneOfEachEvent()
+	match.Winner = match.TeamA
+
+	expectedTypes := map[exportColumnType][]reflect.Type{
+		exportColumnTypeString:    {reflect.TypeFor[string]()},
+		exportColumnTypeEnum:      {reflect.TypeFor[string]()},
+		exportColumnTypeInt:       {reflect.TypeFor[int](), reflect.TypeFor[int64]()},
+		exportColumnTypeUint64:    {reflect.TypeFor[uint64]()},
+		exportColumnTypeFloat32:   {reflect.TypeFor[float32]()},
+		exportColumnTypeFloat64:   {reflect.TypeFor[float64]()},
+		exportColumnTypeBool:      {reflect.TypeFor[bool]()},
+		exportColumnTypeTimestamp: {reflect.TypeFor[time.Time]()},
+	}
+
+	tables := buildExportTables(match)
+	if len(tables) != 33 {
+		t.Errorf("expected 33 tables, got %d", len(tables))
+	}
+
+	for _, table := range tables {
+		if table.rowCount == 0 {
+			t.Errorf("table %s: expected at least 1 row", table.name)
+			continue
+		}
+
+		values := table.row(0)
+		if len(values) != len(table.columns) {
+			t.Errorf("table %s: expected %d values, got %d", table.name, len(table.columns), len(values))
+			continue
+		}
+
+		for index, column := range table.columns {
+			valueType := reflect.TypeOf(values[index])
+			if !slices.Contains(expectedTypes[column.columnType], valueType) {
+				t.Errorf("table %s: column %q has a value of type %s", table.name, column.name, valueType)
+			}
+		}
+	}
+}
+
+func TestExportTablesValuesMatchHeaders(t *testing.T) {
+	match := newMatch(constants.DemoSourceValve, &demo.Demo{})
+	match.Checksum = "checksum"
+	match.TickCount = 1
+	match.TickRate = 2
+	match.FrameRate = 3
+	match.Duration = 4 * time.Second
+	match.NetworkProtocol = 5
+	match.BuildNumber = 6
+	match.GameType = constants.GameTypeGunGame
+	match.OvertimeCount = 7
+	match.MaxRounds = 8
+	match.Shots = append(match.Shots, &Shot{
+		Frame:           1,
+		Tick:            2,
+		RoundNumber:     3,
+		ProjectileID:    4,
+		X:               5,
+		Y:               6,
+		Z:               7,
+		PlayerSteamID64: 8,
+		PlayerVelocityX: 9,
+		PlayerVelocityY: 10,
+		PlayerVelocityZ: 11,
+		Yaw:             12,
+		Pitch:           13,
+		RecoilIndex:     14,
+		AimPunchAngleX:  15,
+		AimPunchAngleY:  16,
+		ViewPunchAngleX: 17,
+		ViewPunchAngleY: 18,
+	})
+
+	expectedValues := map[string]map[string]any{
+		"match": {
+			"checksum":         "checksum",
+			"tick count":       1,
+			"tickrate":         float64(2),
+			"framerate":        float64(3),
+			"game type":        constants.GameTypeGunGame.String(),
+			"duration":         float64(4),
+			"network protocol": 5,
+			"build number":     6,
+			"overtime count":   7,
+			"max rounds":       8,
+		},
+		"shots": {
+			"frame":              1,
+			"tick":               2,
+			"round":              3,
+			"projectile id":      int64(4),
+			"x":                  float64(5),
+			"y":                  float64(6),
+			"z":                  float64(7),
+			"player steamid":     uint64(8),
+			"player velocity x":  float64(9),
+			"player velocity y":  float64(10),
+			"player velocity z":  float64(11),
+			"yaw":                float32(12),
+			"pitch":              float32(13),
+			"recoil index":       float32(14),
+			"aim punch angle x":  float64(15),
+			"aim punch angle y":  float64(16),
+			"view punch angle x": float64(17),
+			"view punch angle y": float64(18),
+			"match checksum":     "checksum",
+		},
+	}
+
+	for _, table := range []exportTable{buildMatchTable(&match), buildShotsTable(&match)} {
+		values := table.row(0)
+		if len(values) != len(table.columns) {
+			t.Fatalf("table %s: expected %d values, got %d", table.name, len(table.columns), len(values))
+		}
+
+		var checkedColumnCount int
+		for index, column := range table.columns {
+			expectedValue, ok := expectedValues[table.name][column.name]
+			if !ok {
+				continue
+			}
+			checkedColumnCount++
+			if values[index] != expectedValue {
+				t.Errorf("table %s: expected %v in column %q, got %v", table.name, expectedValue, column.name, values[index])
+			}
+		}
+		if checkedColumnCount != len(expectedValues[table.name]) {
+			t.Errorf("table %s: expected %d columns to be checked, got %d", table.name, len(expectedValues[table.name]), checkedColumnCount)
+		}
+	}
+}
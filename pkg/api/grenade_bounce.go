@@ -1,8 +1,6 @@
 package api
 
 import (
-	"fmt"
-
 	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
 	"github.com/markus-wa/demoinfocs-golang/v4/pkg/demoinfocs/common"
 )
@@ -30,21 +28,21 @@ type GrenadeBounce struct {
 
 func newGrenadeBounceFromProjectile(analyzer *Analyzer, projectile *common.GrenadeProjectile) *GrenadeBounce {
 	if projectile == nil {
-		fmt.Println("Projectile nil in grenade projectile bounce event")
+		analyzer.addDiagnostic(constants.DiagnosticSeverityWarning, constants.DiagnosticCodeMissingGrenade, "Projectile nil in grenade projectile bounce event")
 		return nil
 	}
 
 	if projectile.WeaponInstance == nil {
-		fmt.Println("Projectile weapon instance nil in grenade projectile bounce event")
+		analyzer.addDiagnostic(constants.DiagnosticSeverityWarning, constants.DiagnosticCodeMissingGrenade, "Projectile weapon instance nil in grenade projectile bounce event")
 		return nil
 	}
 
 	thrower := projectile.Thrower
 	if thrower == nil {
-		fmt.Println("Thrower nil in grenade projectile bounce event, falling back to owner")
+		analyzer.addDiagnostic(constants.DiagnosticSeverityInfo, constants.DiagnosticCodeMissingThrower, "Thrower nil in grenade projectile bounce event, falling back to owner")
 		thrower = projectile.WeaponInstance.Owner
 		if thrower == nil {
-			fmt.Println("Owner nil in grenade projectile bounce event")
+			analyzer.addDiagnostic(constants.DiagnosticSeverityWarning, constants.DiagnosticCodeMissingThrower, "Owner nil in grenade projectile bounce event")
 			return nil
 		}
 	}
@@ -1,8 +1,6 @@
 package api
 
 import (
-	"fmt"
-
 	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
 	"github.com/markus-wa/demoinfocs-golang/v4/pkg/demoinfocs/common"
 )
@@ -30,16 +28,16 @@ type GrenadePosition struct {
 
 func newGrenadePositionFromProjectile(analyzer *Analyzer, projectile *common.GrenadeProjectile) *GrenadePosition {
 	if projectile.WeaponInstance == nil {
-		fmt.Println("Projectile weapon instance nil in grenade projectile position")
+		analyzer.addDiagnostic(constants.DiagnosticSeverityWarning, constants.DiagnosticCodeMissingGrenade, "Projectile weapon instance nil in grenade projectile position")
 		return nil
 	}
 
 	thrower := projectile.Thrower
 	if thrower == nil {
-		fmt.Println("Thrower nil in grenade projectile position, falling back to owner")
+		analyzer.addDiagnostic(constants.DiagnosticSeverityInfo, constants.DiagnosticCodeMissingThrower, "Thrower nil in grenade projectile position, falling back to owner")
 		thrower = projectile.WeaponInstance.Owner
 		if thrower == nil {
-			fmt.Println("Owner nil in grenade projectile position")
+			analyzer.addDiagnostic(constants.DiagnosticSeverityWarning, constants.DiagnosticCodeMissingThrower, "Owner nil in grenade projectile position")
 			return nil
 		}
 	}
@@ -1,8 +1,6 @@
 package api
 
 import (
-	"fmt"
-
 	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
 	"github.com/markus-wa/demoinfocs-golang/v4/pkg/demoinfocs/common"
 )
@@ -30,21 +28,21 @@ type GrenadeProjectileDestroy struct {
 
 func newGrenadeProjectileDestroyFromProjectile(analyzer *Analyzer, projectile *common.GrenadeProjectile) *GrenadeProjectileDestroy {
 	if projectile == nil {
-		fmt.Println("Projectile nil in grenade projectile destroy creation")
+		analyzer.addDiagnostic(constants.DiagnosticSeverityWarning, constants.DiagnosticCodeMissingGrenade, "Projectile nil in grenade projectile destroy creation")
 		return nil
 	}
 
 	thrower := projectile.Thrower
 	if thrower == nil {
-		fmt.Println("Thrower nil in grenade projectile destroy creation, falling back to owner")
+		analyzer.addDiagnostic(constants.DiagnosticSeverityInfo, constants.DiagnosticCodeMissingThrower, "Thrower nil in grenade projectile destroy creation, falling back to owner")
 		if projectile.WeaponInstance == nil {
-			fmt.Println("Projectile weapon instance nil in grenade projectile destroy creation")
+			analyzer.addDiagnostic(constants.DiagnosticSeverityWarning, constants.DiagnosticCodeMissingGrenade, "Projectile weapon instance nil in grenade projectile destroy creation")
 			return nil
 		}
 
 		thrower = projectile.WeaponInstance.Owner
 		if thrower == nil {
-			fmt.Println("Owner nil in grenade projectile destroy creation")
+			analyzer.addDiagnostic(constants.DiagnosticSeverityWarning, constants.DiagnosticCodeMissingThrower, "Owner nil in grenade projectile destroy creation")
 			return nil
 		}
 	}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"time"
+
+	"github.com/akiver/cs-demo-analyzer/internal/demo"
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+)
+
+// DemoMatchInfo contains the values of the .info file associated with Valve demos.
+type DemoMatchInfo struct {
+	MatchID       uint64        `json:"matchId"`
+	ReservationID uint64        `json:"reservationId"`
+	Date          time.Time     `json:"date"`
+	MapName       string        `json:"mapName"`
+	ServerIP      string        `json:"serverIp"`
+	TvPort        uint32        `json:"tvPort"`
+	TeamScores    []int         `json:"teamScores"`
+	Duration      time.Duration `json:"duration"`
+}
+
+// DemoInfo contains the values available without analyzing the demo, they come from the demo header and its .info
+// file.
+type DemoInfo struct {
+	Checksum        string               `json:"checksum"`
+	Game            constants.Game       `json:"game"`
+	DemoFilePath    string               `json:"demoFilePath"`
+	DemoFileName    string               `json:"demoFileName"`
+	Source          constants.DemoSource `json:"source"` // Detected source, unknown if it can't be detected
+	Type            constants.DemoType   `json:"type"`
+	MapName         string               `json:"mapName"`
+	ShareCode       string               `json:"shareCode"` // Valve demos only, the .info file must be next to the .dem file to be able to generate it
+	Size            int64                `json:"size"`
+	TickCount       int                  `json:"tickCount"` // CSGO only
+	TickRate        float64              `json:"tickrate"`  // CSGO only
+	FrameRate       float64              `json:"framerate"` // CSGO only
+	Date            time.Time            `json:"date"`
+	Duration        time.Duration        `json:"duration"` // CSGO only
+	ServerName      string               `json:"serverName"`
+	ClientName      string               `json:"clientName"`
+	NetworkProtocol int                  `json:"networkProtocol"`
+	BuildNumber     int                  `json:"buildNumber"` // CS2 only
+	MatchInfo       *DemoMatchInfo       `json:"matchInfo"`   // nil if the .info file is not next to the .dem file
+	Warnings        []string             `json:"warnings"`
+}
+
+func newDemoInfo(demoInfo *demo.Demo) *DemoInfo {
+	info := &DemoInfo{
+		Checksum:        demoInfo.Checksum,
+		Game:            getDemoGame(demoInfo),
+		DemoFilePath:    demoInfo.FilePath,
+		DemoFileName:    demoInfo.FileName,
+		Source:          demo.GetDemoSource(demoInfo),
+		Type:            demoInfo.Type,
+		MapName:         demoInfo.MapName,
+		ShareCode:       demoInfo.ShareCode,
+		Size:            demoInfo.Size,
+		TickCount:       demoInfo.TickCount,
+		TickRate:        demoInfo.TickRate,
+		FrameRate:       demoInfo.FrameRate,
+		Date:            demoInfo.Date,
+		Duration:        demoInfo.Duration,
+		ServerName:      demoInfo.ServerName,
+		ClientName:      demoInfo.ClientName,
+		NetworkProtocol: demoInfo.NetworkProtocol,
+		BuildNumber:     demoInfo.BuildNumber,
+		Warnings:        demoInfo.Warnings,
+	}
+
+	if matchInfo := demoInfo.MatchInfo; matchInfo != nil {
+		info.MatchInfo = &DemoMatchInfo{
+			MatchID:       matchInfo.MatchID,
+			ReservationID: matchInfo.ReservationID,
+			Date:          matchInfo.Date,
+			MapName:       matchInfo.MapName,
+			ServerIP:      matchInfo.ServerIP,
+			TvPort:        matchInfo.TvPort,
+			TeamScores:    matchInfo.TeamScores,
+			Duration:      matchInfo.Duration,
+		}
+	}
+
+	return info
+}
+
+// InspectDemo reads the header of the demo located at the given path without analyzing it, it takes a few
+// milliseconds. Compressed demos must be decompressed entirely to compute their checksum.
+// An error is returned if the file is an archive that contains several demos, use InspectDemos in this case.
+func InspectDemo(demoPath string) (*DemoInfo, error) {
+	demoInfo, err := demo.GetDemoFromPath(demoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDemoInfo(demoInfo), nil
+}
+
+// InspectDemos is like InspectDemo but it returns the header of all demos contained in the file, i.e. a zip archive
+// containing the demos of a series.
+func InspectDemos(demoPath string) ([]*DemoInfo, error) {
+	demos, err := demo.GetDemosFromPath(demoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*DemoInfo, 0, len(demos))
+	for _, demoInfo := range demos {
+		infos = append(infos, newDemoInfo(demoInfo))
+	}
+
+	return infos, nil
+}
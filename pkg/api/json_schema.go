@@ -0,0 +1,175 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// OutputSchemaVersion is the version of the JSON export schema, it's written in the outputSchemaVersion field of the
+// export. It must be incremented every time a field of the JSON export is added, removed, renamed or changes type.
+const OutputSchemaVersion = 3
+
+const jsonSchemaID = "https://raw.githubusercontent.com/akiver/cs-demo-analyzer/main/schema/match.schema.json"
+
+// jsonSchemaMarshalTypes contains the structs that implement json.Marshaler, their schema is built from the struct
+// actually marshaled which includes computed fields.
+var jsonSchemaMarshalTypes = map[reflect.Type]reflect.Type{
+	reflect.TypeFor[Match]():  reflect.TypeFor[MatchJSON](),
+	reflect.TypeFor[Player](): reflect.TypeFor[PlayerJSON](),
+	reflect.TypeFor[Round]():  reflect.TypeFor[RoundJSON](),
+}
+
+type jsonSchemaBuilder struct {
+	definitions map[string]any
+}
+
+// schemaOf returns the schema of a type, named structs are added to the definitions and referenced.
+func (builder *jsonSchemaBuilder) schemaOf(valueType reflect.Type) map[string]any {
+	switch valueType {
+	case reflect.TypeFor[time.Time]():
+		return map[string]any{"type": "string", "format": "date-time"}
+	case reflect.TypeFor[time.Duration]():
+		return map[string]any{"type": "integer", "description": "Duration in nanoseconds"}
+	}
+
+	switch valueType.Kind() {
+	case reflect.Pointer:
+		return nullableJSONSchema(builder.schemaOf(valueType.Elem()))
+	case reflect.Slice, reflect.Array:
+		// nil slices are marshaled as null.
+		return map[string]any{"type": []string{"array", "null"}, "items": builder.schemaOf(valueType.Elem())}
+	case reflect.Map:
+		return map[string]any{
+			"type":                 []string{"object", "null"},
+			"additionalProperties": builder.schemaOf(valueType.Elem()),
+		}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Struct:
+		name := valueType.Name()
+		if _, exists := builder.definitions[name]; !exists {
+			// Register the name first to support recursive types.
+			builder.definitions[name] = nil
+			structType := valueType
+			if marshalType, ok := jsonSchemaMarshalTypes[valueType]; ok {
+				structType = marshalType
+			}
+			builder.definitions[name] = builder.structSchema(structType)
+		}
+
+		return map[string]any{"$ref": "#/$defs/" + name}
+	}
+
+	panic("unsupported JSON schema type " + valueType.String())
+}
+
+// structSchema returns the schema of a struct following the encoding/json rules, fields of embedded structs are
+// promoted unless a field with the same name is declared by the outer struct.
+func (builder *jsonSchemaBuilder) structSchema(structType reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+	var embeddedTypes []reflect.Type
+	for index := range structType.NumField() {
+		field := structType.Field(index)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embeddedType := field.Type
+			if embeddedType.Kind() == reflect.Pointer {
+				embeddedType = embeddedType.Elem()
+			}
+			if embeddedType.Kind() == reflect.Struct {
+				embeddedTypes = append(embeddedTypes, embeddedType)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = builder.schemaOf(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	for _, embeddedType := range embeddedTypes {
+		embeddedSchema := builder.structSchema(embeddedType)
+		for name, property := range embeddedSchema["properties"].(map[string]any) {
+			if _, exists := properties[name]; !exists {
+				properties[name] = property
+			}
+		}
+		for _, name := range embeddedSchema["required"].([]string) {
+			if !slices.Contains(required, name) {
+				required = append(required, name)
+			}
+		}
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+func nullableJSONSchema(schema map[string]any) map[string]any {
+	if schemaType, ok := schema["type"].(string); ok {
+		nullable := make(map[string]any, len(schema))
+		for key, value := range schema {
+			nullable[key] = value
+		}
+		nullable["type"] = []string{schemaType, "null"}
+
+		return nullable
+	}
+
+	if _, ok := schema["type"]; ok {
+		return schema
+	}
+
+	return map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+}
+
+// JSONSchema returns the JSON Schema (draft 2020-12) of the JSON export.
+func JSONSchema() ([]byte, error) {
+	builder := jsonSchemaBuilder{definitions: make(map[string]any)}
+	schema := map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id":     jsonSchemaID,
+		"title":   "Match",
+		"$ref":    builder.schemaOf(reflect.TypeFor[Match]())["$ref"],
+		"$defs":   builder.definitions,
+	}
+	// Consumers can rely on the version to detect the schema of an export.
+	matchProperties := builder.definitions["Match"].(map[string]any)["properties"].(map[string]any)
+	matchProperties["outputSchemaVersion"] = map[string]any{"type": "integer", "const": OutputSchemaVersion}
+
+	content, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(content, '\n'), nil
+}
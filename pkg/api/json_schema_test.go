@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestJSONSchema fails when the JSON export changed without updating the schema file, the OutputSchemaVersion
+// constant must be incremented before updating it with the -update flag.
+func TestJSONSchema(t *testing.T) {
+	schemaFilePath := filepath.Join("..", "..", "schema", "match.schema.json")
+	schema, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(schemaFilePath)
+	if err != nil && !(*update && os.IsNotExist(err)) {
+		t.Fatal(err)
+	}
+
+	if string(content) == string(schema) {
+		return
+	}
+
+	if content != nil {
+		var publishedSchema struct {
+			Defs struct {
+				Match struct {
+					Properties struct {
+						OutputSchemaVersion struct {
+							Const int `json:"const"`
+						} `json:"outputSchemaVersion"`
+					} `json:"properties"`
+				} `json:"Match"`
+			} `json:"$defs"`
+		}
+		err = json.Unmarshal(content, &publishedSchema)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if publishedSchema.Defs.Match.Properties.OutputSchemaVersion.Const == OutputSchemaVersion {
+			t.Fatalf("the JSON export changed, increment OutputSchemaVersion (%d) and run \"go test ./pkg/api -run TestJSONSchema -update\" to update %s", OutputSchemaVersion, schemaFilePath)
+		}
+	}
+
+	if !*update {
+		t.Fatalf("%s is outdated, run \"go test ./pkg/api -run TestJSONSchema -update\" to update it", schemaFilePath)
+	}
+
+	err = os.MkdirAll(filepath.Dir(schemaFilePath), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(schemaFilePath, schema, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
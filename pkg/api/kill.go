@@ -1,8 +1,6 @@
 package api
 
 import (
-	"fmt"
-
 	"github.com/akiver/cs-demo-analyzer/internal/math"
 	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
 	"github.com/golang/geo/r3"
@@ -65,11 +63,11 @@ func (kill *Kill) IsTeamKill() bool {
 
 func newKillFromGameEvent(analyzer *Analyzer, event events.Kill) *Kill {
 	if event.Weapon == nil {
-		fmt.Println("Player kill event without weapon occurred")
+		analyzer.addDiagnostic(constants.DiagnosticSeverityWarning, constants.DiagnosticCodeMissingWeapon, "Player kill event without weapon occurred")
 		return nil
 	}
 	if event.Victim == nil {
-		fmt.Println("Player kill event without victim occurred")
+		analyzer.addDiagnostic(constants.DiagnosticSeverityWarning, constants.DiagnosticCodeMissingPlayer, "Player kill event without victim occurred")
 		return nil
 	}
 	parser := analyzer.parser
@@ -2,7 +2,6 @@ package api
 
 import (
 	"encoding/json"
-	"fmt"
 	"time"
 
 	"github.com/akiver/cs-demo-analyzer/internal/demo"
@@ -15,63 +14,66 @@ import (
 // Match is the root struct that contains relevant data from a demo.
 // It excludes data from warmup / halftime / after match.
 type Match struct {
-	Checksum                  string                      `json:"checksum"`
-	Game                      constants.Game              `json:"game"`
-	DemoFilePath              string                      `json:"demoFilePath"`
-	DemoFileName              string                      `json:"demoFileName"`
-	Source                    constants.DemoSource        `json:"source"`
-	Type                      constants.DemoType          `json:"type"`
-	MapName                   string                      `json:"mapName"`
-	ShareCode                 string                      `json:"shareCode"` // Valve demos only, the .info file must be next to the .dem file to be able to generate it
-	TickCount                 int                         `json:"tickCount"`
-	TickRate                  float64                     `json:"tickrate"`
-	FrameRate                 float64                     `json:"framerate"`
-	Date                      time.Time                   `json:"date"`
-	Duration                  time.Duration               `json:"duration"`
-	ServerName                string                      `json:"serverName"`
-	ClientName                string                      `json:"clientName"`
-	NetworkProtocol           int                         `json:"networkProtocol"`
-	BuildNumber               int                         `json:"buildNumber"` // CS2 only
-	GameType                  constants.GameType          `json:"gameType"`
-	GameMode                  constants.GameMode          `json:"gameMode"`
-	gameModeStr               constants.GameModeStr       // CS2 only, the game mode as a string coming from the CSVCMsg_ServerInfo msg
-	IsRanked                  bool                        `json:"isRanked"`
-	MaxRounds                 int                         `json:"maxRounds"` // mp_maxrounds if detected or based on final scores
-	OvertimeCount             int                         `json:"overtimeCount"`
-	HasVacLiveBan             bool                        `json:"hasVacLiveBan"`
-	TeamA                     *Team                       `json:"teamA"` // Team A is the Team that started as CT
-	TeamB                     *Team                       `json:"teamB"` // Team B is the Team that started as T
-	Winner                    *Team                       `json:"winner"`
-	PlayersBySteamID          map[uint64]*Player          `json:"players"`
-	Kills                     []*Kill                     `json:"kills"` // Includes suicides and bomb explosions too
-	Shots                     []*Shot                     `json:"shots"`
-	Rounds                    []*Round                    `json:"rounds"`
-	Clutches                  []*Clutch                   `json:"clutches"`
-	BombsPlanted              []*BombPlanted              `json:"bombsPlanted"`
-	BombsDefused              []*BombDefused              `json:"bombsDefused"`
-	BombsExploded             []*BombExploded             `json:"bombsExploded"`
-	BombsPlantStart           []*BombPlantStart           `json:"bombsPlantStart"`
-	BombsDefuseStart          []*BombDefuseStart          `json:"bombsDefuseStart"`
-	PlayersFlashed            []*PlayerFlashed            `json:"playersFlashed"`
-	GrenadePositions          []*GrenadePosition          `json:"grenadePositions"`
-	InfernoPositions          []*InfernoPosition          `json:"infernoPositions"`
-	HostagePickUpStart        []*HostagePickUpStart       `json:"hostagePickUpStart"`
-	HostagePickedUp           []*HostagePickedUp          `json:"hostagePickedUp"`
-	HostageRescued            []*HostageRescued           `json:"hostageRescued"`
-	HostagePositions          []*HostagePosition          `json:"hostagePositions"`
-	SmokesStart               []*SmokeStart               `json:"smokesStart"`
-	DecoysStart               []*DecoyStart               `json:"decoysStart"`
-	HeGrenadesExplode         []*HeGrenadeExplode         `json:"heGrenadesExplode"`
-	FlashbangsExplode         []*FlashbangExplode         `json:"flashbangsExplode"`
-	GrenadeBounces            []*GrenadeBounce            `json:"grenadeBounces"`
-	GrenadeProjectilesDestroy []*GrenadeProjectileDestroy `json:"grenadeProjectilesDestroy"`
-	ChickenPositions          []*ChickenPosition          `json:"chickenPositions"`
-	ChickenDeaths             []*ChickenDeath             `json:"chickenDeaths"`
-	Damages                   []*Damage                   `json:"damages"`
-	PlayerPositions           []*PlayerPosition           `json:"playerPositions"`
-	PlayersBuy                []*PlayerBuy                `json:"playersBuy"`
-	PlayerEconomies           []*PlayerEconomy            `json:"playerEconomies"`
-	ChatMessages              []*ChatMessage              `json:"chatMessages"`
+	Checksum                  string                           `json:"checksum"`
+	ContentHash               string                           `json:"contentHash"` // SHA-256 of the demo, set only when the ComputeContentHash option is enabled
+	Game                      constants.Game                   `json:"game"`
+	DemoFilePath              string                           `json:"demoFilePath"`
+	DemoFileName              string                           `json:"demoFileName"`
+	Source                    constants.DemoSource             `json:"source"`
+	Type                      constants.DemoType               `json:"type"`
+	MapName                   string                           `json:"mapName"`
+	ShareCode                 string                           `json:"shareCode"` // Valve demos only, the .info file must be next to the .dem file to be able to generate it
+	TickCount                 int                              `json:"tickCount"`
+	TickRate                  float64                          `json:"tickrate"`
+	FrameRate                 float64                          `json:"framerate"`
+	Date                      time.Time                        `json:"date"`
+	Duration                  time.Duration                    `json:"duration"`
+	ServerName                string                           `json:"serverName"`
+	ClientName                string                           `json:"clientName"`
+	NetworkProtocol           int                              `json:"networkProtocol"`
+	BuildNumber               int                              `json:"buildNumber"` // CS2 only
+	GameType                  constants.GameType               `json:"gameType"`
+	GameMode                  constants.GameMode               `json:"gameMode"`
+	gameModeStr               constants.GameModeStr            // CS2 only, the game mode as a string coming from the CSVCMsg_ServerInfo msg
+	eventCategories           map[constants.EventCategory]bool // Categories collected, nil if no categories have been included/excluded
+	IsRanked                  bool                             `json:"isRanked"`
+	MaxRounds                 int                              `json:"maxRounds"` // mp_maxrounds if detected or based on final scores
+	OvertimeCount             int                              `json:"overtimeCount"`
+	HasVacLiveBan             bool                             `json:"hasVacLiveBan"`
+	TeamA                     *Team                            `json:"teamA"` // Team A is the Team that started as CT
+	TeamB                     *Team                            `json:"teamB"` // Team B is the Team that started as T
+	Winner                    *Team                            `json:"winner"`
+	PlayersBySteamID          map[uint64]*Player               `json:"players"`
+	Kills                     []*Kill                          `json:"kills"` // Includes suicides and bomb explosions too
+	Shots                     []*Shot                          `json:"shots"`
+	Rounds                    []*Round                         `json:"rounds"`
+	Clutches                  []*Clutch                        `json:"clutches"`
+	BombsPlanted              []*BombPlanted                   `json:"bombsPlanted"`
+	BombsDefused              []*BombDefused                   `json:"bombsDefused"`
+	BombsExploded             []*BombExploded                  `json:"bombsExploded"`
+	BombsPlantStart           []*BombPlantStart                `json:"bombsPlantStart"`
+	BombsDefuseStart          []*BombDefuseStart               `json:"bombsDefuseStart"`
+	PlayersFlashed            []*PlayerFlashed                 `json:"playersFlashed"`
+	GrenadePositions          []*GrenadePosition               `json:"grenadePositions"`
+	InfernoPositions          []*InfernoPosition               `json:"infernoPositions"`
+	HostagePickUpStart        []*HostagePickUpStart            `json:"hostagePickUpStart"`
+	HostagePickedUp           []*HostagePickedUp               `json:"hostagePickedUp"`
+	HostageRescued            []*HostageRescued                `json:"hostageRescued"`
+	HostagePositions          []*HostagePosition               `json:"hostagePositions"`
+	SmokesStart               []*SmokeStart                    `json:"smokesStart"`
+	DecoysStart               []*DecoyStart                    `json:"decoysStart"`
+	HeGrenadesExplode         []*HeGrenadeExplode              `json:"heGrenadesExplode"`
+	FlashbangsExplode         []*FlashbangExplode              `json:"flashbangsExplode"`
+	GrenadeBounces            []*GrenadeBounce                 `json:"grenadeBounces"`
+	GrenadeProjectilesDestroy []*GrenadeProjectileDestroy      `json:"grenadeProjectilesDestroy"`
+	ChickenPositions          []*ChickenPosition               `json:"chickenPositions"`
+	ChickenDeaths             []*ChickenDeath                  `json:"chickenDeaths"`
+	Damages                   []*Damage                        `json:"damages"`
+	PlayerPositions           []*PlayerPosition                `json:"playerPositions"`
+	PlayersBuy                []*PlayerBuy                     `json:"playersBuy"`
+	PlayerEconomies           []*PlayerEconomy                 `json:"playerEconomies"`
+	ChatMessages              []*ChatMessage                   `json:"chatMessages"`
+	Diagnostics               []*Diagnostic                    `json:"diagnostics"` // Kept when the match restarts
 	scoreTeamA                *int
 	scoreTeamB                *int
 }
@@ -80,17 +82,20 @@ type MatchAlias Match
 
 type MatchJSON struct {
 	*MatchAlias
-	GameModeStr string `json:"gameModeStr"`
+	OutputSchemaVersion int    `json:"outputSchemaVersion"`
+	GameModeStr         string `json:"gameModeStr"`
 }
 
 func (match *Match) MarshalJSON() ([]byte, error) {
 
 	return json.Marshal(MatchJSON{
-		MatchAlias:  (*MatchAlias)(match),
-		GameModeStr: match.GameModeStr().String(),
+		MatchAlias:          (*MatchAlias)(match.withoutExcludedEvents()),
+		OutputSchemaVersion: OutputSchemaVersion,
+		GameModeStr:         match.GameModeStr().String(),
 	})
 }
 
+// GameModeStr returns the game mode as a string, competitive if it's unknown.
 func (match Match) GameModeStr() constants.GameModeStr {
 	if match.gameModeStr != "" {
 		return match.gameModeStr
@@ -101,11 +106,13 @@ func (match Match) GameModeStr() constants.GameModeStr {
 		return gameModeStr
 	}
 
-	fmt.Printf("Unknown game mode string for game type: %d and game mode: %d", match.GameType, match.GameMode)
-
 	return constants.GameModeStrCompetitive
 }
 
+func (match Match) isGameModeKnown() bool {
+	return match.gameModeStr != "" || constants.GameModeMapping[match.GameType][match.GameMode] != ""
+}
+
 func (match Match) Players() []*Player {
 	players := make([]*Player, 0, len(match.PlayersBySteamID))
 	for _, player := range match.PlayersBySteamID {
@@ -259,17 +266,21 @@ func (match *Match) initTeams() {
 	match.scoreTeamB = &teamB.Score
 }
 
-func newMatch(source constants.DemoSource, demoInfo *demo.Demo) Match {
-	game := constants.CSGO
-	if demoInfo.IsSource2() {
-		// The build number of CS2 when it was publicly available is 9832, everything below is coming from the limited test.
-		if demoInfo.BuildNumber < 9832 {
-			game = constants.CS2LT
-		} else {
-			game = constants.CS2
-		}
+func getDemoGame(demoInfo *demo.Demo) constants.Game {
+	if !demoInfo.IsSource2() {
+		return constants.CSGO
 	}
 
+	// The build number of CS2 when it was publicly available is 9832, everything below is coming from the limited test.
+	if demoInfo.BuildNumber < 9832 {
+		return constants.CS2LT
+	}
+
+	return constants.CS2
+}
+
+func newMatch(source constants.DemoSource, demoInfo *demo.Demo) Match {
+	game := getDemoGame(demoInfo)
 	match := Match{
 		Checksum:                  demoInfo.Checksum,
 		Source:                    source,
@@ -316,6 +327,7 @@ func newMatch(source constants.DemoSource, demoInfo *demo.Demo) Match {
 		HostagePositions:          []*HostagePosition{},
 		ChickenPositions:          []*ChickenPosition{},
 		ChatMessages:              []*ChatMessage{},
+		Diagnostics:               []*Diagnostic{},
 		ChickenDeaths:             []*ChickenDeath{},
 		GrenadeProjectilesDestroy: []*GrenadeProjectileDestroy{},
 	}
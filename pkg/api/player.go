@@ -664,15 +664,15 @@ func (player *Player) FiveKillCount() int {
 // This returns the "impact" as described in the following blog post.
 // https://flashed.gg/posts/reverse-engineering-hltv-rating/
 // 2.13*KPR + 0.42*Assist per Round -0.41 ≈ impact
-func (player *Player) impact() float32 {
-	return float32(2.13*player.AverageKillPerRound()) + float32(0.42*player.AverageAssistPerRound()) + -0.41
+func computeImpact(killPerRound float32, assistPerRound float32) float32 {
+	return float32(2.13*killPerRound) + float32(0.42*assistPerRound) + -0.41
 }
 
-// This returns the player's HLTV rating 2.0.
+// This returns the HLTV rating 2.0, it's shared with series players.
 // https://flashed.gg/posts/reverse-engineering-hltv-rating/
 // 0.0073*KAST + 0.3591*KPR + -0.5329*DPR + 0.2372*Impact + 0.0032*ADR + 0.1587 ≈ Rating 2.0
-func (player *Player) HltvRating2() float32 {
-	rating := float32(0.0073*player.KAST()) + float32(0.3591*player.AverageKillPerRound()) + float32(-0.5329*player.AverageDeathPerRound()) + float32(0.2372*player.impact()) + float32(0.0032*player.AverageDamagePerRound()) + 0.1587
+func computeHltvRating2(kast float32, killPerRound float32, assistPerRound float32, deathPerRound float32, damagePerRound float32) float32 {
+	rating := float32(0.0073*kast) + float32(0.3591*killPerRound) + float32(-0.5329*deathPerRound) + float32(0.2372*computeImpact(killPerRound, assistPerRound)) + float32(0.0032*damagePerRound) + 0.1587
 
 	if rating < 0 {
 		return 0
@@ -681,22 +681,36 @@ func (player *Player) HltvRating2() float32 {
 	return rating
 }
 
-// This returns the player's HLTV rating 1.0.
+// This returns the HLTV rating 1.0, xKillCounts[0] is the number of rounds with 1 kill, xKillCounts[4] with 5 kills.
 // Formula: https://web.archive.org/web/20170427062206/http://www.hltv.org/?pageid=242&eventid=0
-func (player *Player) HltvRating() float32 {
-	roundCount := float32(player.roundCount())
+func computeHltvRating(roundCount int, deathCount int, killPerRound float32, xKillCounts [5]int) float32 {
 	if roundCount == 0 {
 		return 0
 	}
 
-	killRating := player.AverageKillPerRound() / 0.679
-	survivalRating := (roundCount - float32(player.DeathCount())) / roundCount / 0.317
-	roundsWithMultipleKillsRating := (float32(player.OneKillCount()) + float32(4*player.TwoKillCount()) + float32(9*player.ThreeKillCount()) + float32(16*player.FourKillCount()) + float32(25*player.FiveKillCount())) / roundCount / 1.277
+	rounds := float32(roundCount)
+	killRating := killPerRound / 0.679
+	survivalRating := (rounds - float32(deathCount)) / rounds / 0.317
+	roundsWithMultipleKillsRating := (float32(xKillCounts[0]) + float32(4*xKillCounts[1]) + float32(9*xKillCounts[2]) + float32(16*xKillCounts[3]) + float32(25*xKillCounts[4])) / rounds / 1.277
 	rating := (killRating + float32(0.7*survivalRating) + roundsWithMultipleKillsRating) / 2.7
 
 	return rating
 }
 
+// This returns the player's HLTV rating 2.0.
+func (player *Player) HltvRating2() float32 {
+	return computeHltvRating2(player.KAST(), player.AverageKillPerRound(), player.AverageAssistPerRound(), player.AverageDeathPerRound(), player.AverageDamagePerRound())
+}
+
+// This returns the player's HLTV rating 1.0.
+func (player *Player) HltvRating() float32 {
+	return computeHltvRating(player.roundCount(), player.DeathCount(), player.AverageKillPerRound(), player.xKillCounts())
+}
+
+func (player *Player) xKillCounts() [5]int {
+	return [5]int{player.OneKillCount(), player.TwoKillCount(), player.ThreeKillCount(), player.FourKillCount(), player.FiveKillCount()}
+}
+
 func (player *Player) roundCount() int {
 	return len(player.match.Rounds)
 }
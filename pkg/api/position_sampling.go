@@ -0,0 +1,100 @@
+package api
+
+import (
+	"errors"
+	"math"
+)
+
+// PositionSampling controls which positions are recorded when positions are included.
+// The zero value records positions at every frame of the whole match.
+type PositionSampling struct {
+	// Record positions every N ticks, 0 or 1 records them at every frame.
+	EveryTicks int
+	// Record positions N times per second of game time, it takes precedence over EveryTicks.
+	Frequency float64
+	// Record positions only between these rounds (inclusive), 0 means no limit.
+	StartRound int
+	EndRound   int
+	// Record positions only between these ticks (inclusive), 0 means no limit.
+	StartTick int
+	EndTick   int
+	// Record only the positions of alive players.
+	AliveOnly bool
+}
+
+func ValidatePositionSampling(sampling PositionSampling) error {
+	if sampling.EveryTicks < 0 || sampling.Frequency < 0 {
+		return errors.New("positions sampling interval must be positive")
+	}
+
+	if sampling.StartRound < 0 || sampling.EndRound < 0 || sampling.StartTick < 0 || sampling.EndTick < 0 {
+		return errors.New("positions rounds and ticks range must be positive")
+	}
+
+	if sampling.EndRound > 0 && sampling.StartRound > sampling.EndRound {
+		return errors.New("positions start round must be lower than or equal to the end round")
+	}
+
+	if sampling.EndTick > 0 && sampling.StartTick > sampling.EndTick {
+		return errors.New("positions start tick must be lower than or equal to the end tick")
+	}
+
+	return nil
+}
+
+// intervalTicks returns the number of ticks between 2 samples.
+func (sampling PositionSampling) intervalTicks(tickRate float64) int {
+	if sampling.Frequency > 0 && tickRate > 0 {
+		return max(int(math.Round(tickRate/sampling.Frequency)), 1)
+	}
+
+	return max(sampling.EveryTicks, 1)
+}
+
+func (sampling PositionSampling) isInRange(roundNumber int, tick int) bool {
+	if roundNumber < sampling.StartRound || (sampling.EndRound > 0 && roundNumber > sampling.EndRound) {
+		return false
+	}
+
+	return tick >= sampling.StartTick && (sampling.EndTick == 0 || tick <= sampling.EndTick)
+}
+
+// positionSampler indicates at which frames positions have to be recorded.
+type positionSampler struct {
+	sampling        PositionSampling
+	lastSampledTick int
+}
+
+func newPositionSampler(sampling PositionSampling) *positionSampler {
+	return &positionSampler{
+		sampling:        sampling,
+		lastSampledTick: -1,
+	}
+}
+
+// shouldSample returns true if positions have to be recorded at the given tick.
+// The tick rate may not be known at the beginning of the parsing, the interval is computed at each frame.
+func (sampler *positionSampler) shouldSample(roundNumber int, tick int, tickRate float64) bool {
+	if !sampler.sampling.isInRange(roundNumber, tick) {
+		return false
+	}
+
+	interval := sampler.sampling.intervalTicks(tickRate)
+	// Ticks may go backwards when the game is restored from a backup.
+	if sampler.lastSampledTick != -1 && tick >= sampler.lastSampledTick && tick-sampler.lastSampledTick < interval {
+		return false
+	}
+
+	sampler.lastSampledTick = tick
+
+	return true
+}
+
+// newPositionSampler returns a function that indicates if positions have to be recorded at the current frame.
+func (analyzer *Analyzer) newPositionSampler(sampling PositionSampling) func() bool {
+	sampler := newPositionSampler(sampling)
+
+	return func() bool {
+		return sampler.shouldSample(analyzer.currentRound.Number, analyzer.currentTick(), analyzer.parser.TickRate())
+	}
+}
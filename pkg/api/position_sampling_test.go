@@ -0,0 +1,83 @@
+package api
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestValidatePositionSampling(t *testing.T) {
+	tests := []struct {
+		name      string
+		sampling  PositionSampling
+		isInvalid bool
+	}{
+		{"zero value", PositionSampling{}, false},
+		{"every ticks", PositionSampling{EveryTicks: 16}, false},
+		{"negative every ticks", PositionSampling{EveryTicks: -1}, true},
+		{"negative frequency", PositionSampling{Frequency: -2}, true},
+		{"negative round", PositionSampling{StartRound: -1}, true},
+		{"rounds range", PositionSampling{StartRound: 3, EndRound: 3}, false},
+		{"inverted rounds range", PositionSampling{StartRound: 4, EndRound: 3}, true},
+		{"start round without end", PositionSampling{StartRound: 4}, false},
+		{"inverted ticks range", PositionSampling{StartTick: 200, EndTick: 100}, true},
+	}
+
+	for _, test := range tests {
+		err := ValidatePositionSampling(test.sampling)
+		if (err != nil) != test.isInvalid {
+			t.Errorf("%s: expected invalid %v, got error %v", test.name, test.isInvalid, err)
+		}
+	}
+}
+
+func TestPositionSampler(t *testing.T) {
+	type frame struct {
+		roundNumber int
+		tick        int
+	}
+	// A frame every 2 ticks during 2 rounds.
+	var frames []frame
+	for tick := 0; tick <= 20; tick += 2 {
+		frames = append(frames, frame{1 + tick/12, tick})
+	}
+
+	tests := []struct {
+		name          string
+		sampling      PositionSampling
+		tickRate      float64
+		frames        []frame
+		expectedTicks []int
+	}{
+		{"every frame", PositionSampling{}, 64, frames, []int{0, 2, 4, 6, 8, 10, 12, 14, 16, 18, 20}},
+		{"every frame with every ticks 1", PositionSampling{EveryTicks: 1}, 64, frames, []int{0, 2, 4, 6, 8, 10, 12, 14, 16, 18, 20}},
+		{"every 5 ticks", PositionSampling{EveryTicks: 5}, 64, frames, []int{0, 6, 12, 18}},
+		{"16 times per second at 64 ticks", PositionSampling{Frequency: 16}, 64, frames, []int{0, 4, 8, 12, 16, 20}},
+		{"frequency takes precedence", PositionSampling{EveryTicks: 10, Frequency: 16}, 64, frames, []int{0, 4, 8, 12, 16, 20}},
+		{"frequency with unknown tick rate", PositionSampling{EveryTicks: 8, Frequency: 16}, 0, frames, []int{0, 8, 16}},
+		{"frequency higher than the tick rate", PositionSampling{Frequency: 128}, 64, frames, []int{0, 2, 4, 6, 8, 10, 12, 14, 16, 18, 20}},
+		{"second round", PositionSampling{StartRound: 2, EndRound: 2}, 64, frames, []int{12, 14, 16, 18, 20}},
+		{"first round", PositionSampling{EndRound: 1}, 64, frames, []int{0, 2, 4, 6, 8, 10}},
+		{"ticks range", PositionSampling{StartTick: 5, EndTick: 12, EveryTicks: 4}, 64, frames, []int{6, 10}},
+		{
+			"ticks going backwards after a backup restoration",
+			PositionSampling{EveryTicks: 10},
+			64,
+			[]frame{{1, 0}, {1, 6}, {1, 10}, {1, 4}, {1, 8}, {1, 14}},
+			[]int{0, 10, 4, 14},
+		},
+	}
+
+	for _, test := range tests {
+		sampler := newPositionSampler(test.sampling)
+		var sampledTicks []int
+		for _, frame := range test.frames {
+			if sampler.shouldSample(frame.roundNumber, frame.tick, test.tickRate) {
+				sampledTicks = append(sampledTicks, frame.tick)
+			}
+		}
+
+		if !slices.Equal(sampledTicks, test.expectedTicks) {
+			t.Errorf("%s: expected ticks %v, got %v", test.name, test.expectedTicks, sampledTicks)
+		}
+	}
+}
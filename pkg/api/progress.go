@@ -0,0 +1,71 @@
+package api
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+
+	events "github.com/markus-wa/demoinfocs-golang/v4/pkg/demoinfocs/events"
+)
+
+// Default minimum duration between 2 progress reports.
+const DefaultProgressInterval = 500 * time.Millisecond
+
+// AnalyzeProgress is reported periodically during the analysis when the Progress option is set.
+type AnalyzeProgress struct {
+	DemoFileName string `json:"demoFileName"`
+	Tick         int    `json:"tick"`
+	Frame        int    `json:"frame"`
+	RoundNumber  int    `json:"roundNumber"`
+	// Between 0 and 1. It comes from the parser when the demo header contains the number of frames, which is usually not
+	// the case with CS2 demos. Otherwise it's computed from the number of bytes read if the demo size is known.
+	Progress float32 `json:"progress"`
+}
+
+// progressReader counts the number of bytes read by the parser.
+type progressReader struct {
+	reader    io.Reader
+	size      int64 // 0 if the demo size is unknown
+	bytesRead atomic.Int64
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.bytesRead.Add(int64(n))
+
+	return n, err
+}
+
+func (analyzer *Analyzer) buildProgress(reader *progressReader) AnalyzeProgress {
+	progress := analyzer.parser.Progress()
+	if progress == 0 && reader.size > 0 {
+		progress = float32(reader.bytesRead.Load()) / float32(reader.size)
+	}
+
+	return AnalyzeProgress{
+		DemoFileName: analyzer.match.DemoFileName,
+		Tick:         analyzer.currentTick(),
+		Frame:        analyzer.parser.CurrentFrame(),
+		RoundNumber:  analyzer.currentRound.Number,
+		Progress:     min(progress, 1),
+	}
+}
+
+// registerProgressHandler calls the Progress option at the end of a frame if at least interval has elapsed since the
+// last report.
+func (analyzer *Analyzer) registerProgressHandler(reader *progressReader, options AnalyzeDemoOptions) {
+	interval := options.ProgressInterval
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+
+	lastReportTime := time.Now()
+	analyzer.parser.RegisterEventHandler(func(event events.FrameDone) {
+		if time.Since(lastReportTime) < interval {
+			return
+		}
+
+		lastReportTime = time.Now()
+		options.Progress(analyzer.buildProgress(reader))
+	})
+}
@@ -0,0 +1,353 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/akiver/cs-demo-analyzer/internal/compression"
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+)
+
+// Name of the series JSON file when the output path is a folder.
+const seriesFileName = "series"
+
+// Series is a match played over several maps (BO1, BO3, BO5...), each map is a separate demo.
+type Series struct {
+	Matches []*Match        `json:"matches"` // Sorted by date, one per map
+	Teams   []*SeriesTeam   `json:"teams"`
+	Players []*SeriesPlayer `json:"players"` // Sorted by HLTV rating 2.0
+	Winner  *SeriesTeam     `json:"winner"`  // nil if no team won more maps than the other ones
+}
+
+// SeriesTeam is a team of the series, teams are matched across maps by their clan name or by their players.
+type SeriesTeam struct {
+	Name           string   `json:"name"`
+	Score          int      `json:"score"` // Number of maps won
+	PlayerSteamIDs []uint64 `json:"playerSteamIds"`
+	hasClanName    bool
+}
+
+// SeriesPlayer holds the stats of a player across all the maps of the series, players are matched by their SteamID.
+type SeriesPlayer struct {
+	SteamID64             uint64  `json:"steamId"`
+	Name                  string  `json:"name"` // Name on the last map
+	TeamName              string  `json:"teamName"`
+	MapCount              int     `json:"mapCount"`
+	RoundCount            int     `json:"roundCount"`
+	KillCount             int     `json:"killCount"`
+	DeathCount            int     `json:"deathCount"`
+	AssistCount           int     `json:"assistCount"`
+	HeadshotCount         int     `json:"headshotCount"`
+	HealthDamage          int     `json:"healthDamage"`
+	KillDeathRatio        float32 `json:"killDeathRatio"`
+	AverageDamagePerRound float32 `json:"averageDamagePerRound"`
+	KAST                  float32 `json:"kast"`
+	HltvRating            float32 `json:"hltvRating"`
+	HltvRating2           float32 `json:"hltvRating2"`
+	team                  *SeriesTeam
+	kastRoundCount        float32
+	xKillCounts           [5]int
+}
+
+func (player *SeriesPlayer) add(mapPlayer *Player) {
+	roundCount := mapPlayer.roundCount()
+	player.Name = mapPlayer.Name
+	player.MapCount++
+	player.RoundCount += roundCount
+	player.KillCount += mapPlayer.KillCount()
+	player.DeathCount += mapPlayer.DeathCount()
+	player.AssistCount += mapPlayer.AssistCount()
+	player.HeadshotCount += mapPlayer.HeadshotCount()
+	player.HealthDamage += mapPlayer.HealthDamage()
+	player.kastRoundCount += mapPlayer.KAST() * float32(roundCount) / 100
+	for index, count := range mapPlayer.xKillCounts() {
+		player.xKillCounts[index] += count
+	}
+}
+
+// computeStats computes the ratios once the stats of all maps have been added, they are weighted by the number of
+// rounds of each map.
+func (player *SeriesPlayer) computeStats() {
+	if player.team != nil {
+		player.TeamName = player.team.Name
+	}
+	if player.DeathCount > 0 {
+		player.KillDeathRatio = float32(player.KillCount) / float32(player.DeathCount)
+	} else {
+		player.KillDeathRatio = float32(player.KillCount)
+	}
+
+	if player.RoundCount == 0 {
+		return
+	}
+
+	roundCount := float32(player.RoundCount)
+	killPerRound := float32(player.KillCount) / roundCount
+	player.AverageDamagePerRound = float32(player.HealthDamage) / roundCount
+	player.KAST = player.kastRoundCount / roundCount * 100
+	player.HltvRating = computeHltvRating(player.RoundCount, player.DeathCount, killPerRound, player.xKillCounts)
+	player.HltvRating2 = computeHltvRating2(
+		player.KAST,
+		killPerRound,
+		float32(player.AssistCount)/roundCount,
+		float32(player.DeathCount)/roundCount,
+		player.AverageDamagePerRound,
+	)
+}
+
+func normalizeClanName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// hasClanName returns false when the team has the default name, i.e. "Team A", because the demo has no clan names.
+func hasClanName(team *Team) bool {
+	return team.Name != "" && team.Name != "Team "+string(team.Letter)
+}
+
+// findTeam returns the series team of the map team, teams with the same clan name first, then the team that has the
+// most players in common with it. It returns nil if the team didn't play a previous map.
+func (series *Series) findTeam(team *Team, steamIDs []uint64, excludedTeam *SeriesTeam) *SeriesTeam {
+	if hasClanName(team) {
+		for _, seriesTeam := range series.Teams {
+			if seriesTeam != excludedTeam && seriesTeam.hasClanName && normalizeClanName(seriesTeam.Name) == normalizeClanName(team.Name) {
+				return seriesTeam
+			}
+		}
+	}
+
+	var bestTeam *SeriesTeam
+	var bestCommonPlayerCount int
+	for _, seriesTeam := range series.Teams {
+		if seriesTeam == excludedTeam {
+			continue
+		}
+
+		var commonPlayerCount int
+		for _, steamID := range steamIDs {
+			if slices.Contains(seriesTeam.PlayerSteamIDs, steamID) {
+				commonPlayerCount++
+			}
+		}
+		if commonPlayerCount > bestCommonPlayerCount {
+			bestTeam = seriesTeam
+			bestCommonPlayerCount = commonPlayerCount
+		}
+	}
+
+	return bestTeam
+}
+
+// addMatch adds the map to the series, matches must be added in the order they have been played.
+func (series *Series) addMatch(match *Match, playersBySteamID map[uint64]*SeriesPlayer) {
+	series.Matches = append(series.Matches, match)
+
+	seriesTeams := make(map[*Team]*SeriesTeam, 2)
+	var previousTeam *SeriesTeam
+	for _, team := range []*Team{match.TeamA, match.TeamB} {
+		var steamIDs []uint64
+		for _, player := range match.PlayersBySteamID {
+			if player.Team == team {
+				steamIDs = append(steamIDs, player.SteamID64)
+			}
+		}
+		slices.Sort(steamIDs)
+
+		seriesTeam := series.findTeam(team, steamIDs, previousTeam)
+		if seriesTeam == nil {
+			seriesTeam = &SeriesTeam{
+				Name:        team.Name,
+				hasClanName: hasClanName(team),
+			}
+			series.Teams = append(series.Teams, seriesTeam)
+		} else if !seriesTeam.hasClanName && hasClanName(team) {
+			seriesTeam.Name = team.Name
+			seriesTeam.hasClanName = true
+		}
+
+		for _, steamID := range steamIDs {
+			if !slices.Contains(seriesTeam.PlayerSteamIDs, steamID) {
+				seriesTeam.PlayerSteamIDs = append(seriesTeam.PlayerSteamIDs, steamID)
+			}
+		}
+		seriesTeams[team] = seriesTeam
+		previousTeam = seriesTeam
+	}
+
+	if match.Winner != nil {
+		if seriesTeam, ok := seriesTeams[match.Winner]; ok {
+			seriesTeam.Score++
+		}
+	}
+
+	for _, player := range match.PlayersBySteamID {
+		seriesPlayer, ok := playersBySteamID[player.SteamID64]
+		if !ok {
+			seriesPlayer = &SeriesPlayer{
+				SteamID64: player.SteamID64,
+			}
+			playersBySteamID[player.SteamID64] = seriesPlayer
+			series.Players = append(series.Players, seriesPlayer)
+		}
+		seriesPlayer.add(player)
+		seriesPlayer.team = seriesTeams[player.Team]
+	}
+}
+
+func newSeries(matches []*Match) *Series {
+	// Demos may not be provided in the order they have been played, i.e. when they are sorted by name.
+	slices.SortStableFunc(matches, func(a *Match, b *Match) int {
+		return a.Date.Compare(b.Date)
+	})
+
+	series := &Series{
+		Matches: make([]*Match, 0, len(matches)),
+		Teams:   make([]*SeriesTeam, 0, 2),
+		Players: make([]*SeriesPlayer, 0),
+	}
+	playersBySteamID := make(map[uint64]*SeriesPlayer)
+	checksums := make(map[string]bool, len(matches))
+	for _, match := range matches {
+		// The same demo may be provided several times, i.e. a demo and its compressed copy.
+		if checksums[match.Checksum] {
+			continue
+		}
+		checksums[match.Checksum] = true
+		series.addMatch(match, playersBySteamID)
+	}
+
+	// No winner if several teams won the same number of maps, i.e. when the series is not complete.
+	var isTied bool
+	for _, team := range series.Teams {
+		slices.Sort(team.PlayerSteamIDs)
+		switch {
+		case series.Winner == nil || team.Score > series.Winner.Score:
+			series.Winner = team
+			isTied = false
+		case team.Score == series.Winner.Score:
+			isTied = true
+		}
+	}
+	if isTied {
+		series.Winner = nil
+	}
+
+	for _, player := range series.Players {
+		player.computeStats()
+	}
+	slices.SortStableFunc(series.Players, func(a *SeriesPlayer, b *SeriesPlayer) int {
+		if a.HltvRating2 != b.HltvRating2 {
+			if a.HltvRating2 > b.HltvRating2 {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return series
+}
+
+type AnalyzeSeriesOptions struct {
+	IncludePositions bool
+	PositionSampling PositionSampling
+	Source           constants.DemoSource
+	IncludeEvents    []constants.EventCategory
+	ExcludeEvents    []constants.EventCategory
+	Progress         func(progress AnalyzeProgress)
+	ProgressInterval time.Duration
+	Logger           DiagnosticLogger
+	// Compute the SHA-256 hash of the demo content, see AnalyzeDemoOptions.
+	ComputeContentHash bool
+	// Folder of the analysis cache, see AnalyzeAndExportDemoOptions.
+	CacheFolderPath string
+}
+
+// AnalyzeSeries analyzes the demos of a series, one per map, and aggregates the stats of the players and the score of
+// the teams across the maps. A path may be an archive that contains several demos of the series, see AnalyzeDemos.
+// Demos with the same checksum are counted once, i.e. when a folder contains a demo and its compressed copy.
+func AnalyzeSeries(demoPaths []string, options AnalyzeSeriesOptions) (*Series, error) {
+	return AnalyzeSeriesContext(context.Background(), demoPaths, options)
+}
+
+// AnalyzeSeriesContext is like AnalyzeSeries but the analysis is stopped when ctx is done, see AnalyzeDemoContext.
+func AnalyzeSeriesContext(ctx context.Context, demoPaths []string, options AnalyzeSeriesOptions) (*Series, error) {
+	if len(demoPaths) == 0 {
+		return nil, errors.New("no demos provided for the series")
+	}
+
+	analyzeOptions := AnalyzeDemoOptions{
+		IncludePositions:   options.IncludePositions,
+		PositionSampling:   options.PositionSampling,
+		Source:             options.Source,
+		IncludeEvents:      options.IncludeEvents,
+		ExcludeEvents:      options.ExcludeEvents,
+		Progress:           options.Progress,
+		ProgressInterval:   options.ProgressInterval,
+		Logger:             options.Logger,
+		ComputeContentHash: options.ComputeContentHash,
+	}
+
+	var matches []*Match
+	addMatch := func(match *Match) error {
+		matches = append(matches, match)
+
+		return nil
+	}
+
+	for _, demoPath := range demoPaths {
+		var err error
+		if options.CacheFolderPath != "" {
+			err = newAnalysisCache(options.CacheFolderPath, analyzeOptions).analyzeDemos(ctx, demoPath, addMatch)
+		} else {
+			err = analyzeDemos(ctx, demoPath, analyzeOptions, addMatch)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newSeries(matches), nil
+}
+
+// ExportSeriesToJSON writes the series as a JSON file, outputPath may be a folder, a file path or StdoutOutputPath.
+// When it's a folder, the file is named series.json.
+func ExportSeriesToJSON(series *Series, outputPath string, minify bool, fileCompression constants.Compression) error {
+	err := ValidateCompression(fileCompression, constants.ExportFormatJSON)
+	if err != nil {
+		return err
+	}
+
+	var file io.WriteCloser
+	if outputPath == StdoutOutputPath {
+		file, err = compression.NewWriter(os.Stdout, fileCompression)
+	} else {
+		stat, statErr := os.Stat(outputPath)
+		if statErr == nil && stat.IsDir() {
+			outputPath = filepath.Join(outputPath, seriesFileName+".json"+compression.Extension(fileCompression))
+		}
+		file, err = compression.CreateFile(outputPath, fileCompression)
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	if !minify {
+		encoder.SetIndent("", "  ")
+	}
+
+	err = encoder.Encode(series)
+	if err != nil {
+		return err
+	}
+
+	return file.Close()
+}
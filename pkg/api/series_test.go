@@ -0,0 +1,159 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/akiver/cs-demo-analyzer/internal/demo"
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+	"github.com/markus-wa/demoinfocs-golang/v4/pkg/demoinfocs/common"
+)
+
+// newSeriesTestMatch returns a map of 2 rounds won by the winner team where player 1 kills player 2 in the first round.
+func newSeriesTestMatch(date time.Time, teamNameA string, teamNameB string, winner constants.TeamLetter, steamIDA uint64, steamIDB uint64) *Match {
+	match := newMatch(constants.DemoSourceValve, &demo.Demo{Date: date, Checksum: date.Format(time.RFC3339)})
+	match.TeamA.Name = teamNameA
+	match.TeamB.Name = teamNameB
+	match.Winner = match.TeamA
+	if winner == constants.TeamLetterB {
+		match.Winner = match.TeamB
+	}
+	match.PlayersBySteamID[steamIDA] = &Player{match: &match, SteamID64: steamIDA, Name: "a", Team: match.TeamA}
+	match.PlayersBySteamID[steamIDB] = &Player{match: &match, SteamID64: steamIDB, Name: "b", Team: match.TeamB}
+	match.Rounds = []*Round{{Number: 1}, {Number: 2}}
+
+	killerSide, victimSide := common.TeamCounterTerrorists, common.TeamTerrorists
+	if steamIDA != 1 {
+		killerSide, victimSide = victimSide, killerSide
+	}
+	match.Kills = []*Kill{{
+		RoundNumber:     1,
+		KillerSteamID64: 1,
+		KillerSide:      killerSide,
+		VictimSteamID64: 2,
+		VictimSide:      victimSide,
+	}}
+	match.Damages = []*Damage{{
+		RoundNumber:       1,
+		AttackerSteamID64: 1,
+		AttackerSide:      killerSide,
+		VictimSteamID64:   2,
+		VictimSide:        victimSide,
+		HealthDamage:      100,
+	}}
+
+	return &match
+}
+
+func TestNewSeries(t *testing.T) {
+	firstMapDate := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	series := newSeries([]*Match{
+		// The clan name of player 1's team is not in the demo of the second map, players are used to find the team.
+		newSeriesTestMatch(firstMapDate.Add(time.Hour), "vitality ", "Team B", constants.TeamLetterB, 2, 1),
+		newSeriesTestMatch(firstMapDate, "NAVI", "Vitality", constants.TeamLetterA, 1, 2),
+	})
+
+	if series.Matches[0].Date != firstMapDate {
+		t.Error("matches are not sorted by date")
+	}
+	if len(series.Teams) != 2 {
+		t.Fatalf("expected 2 teams, got %d", len(series.Teams))
+	}
+	navi, vitality := series.Teams[0], series.Teams[1]
+	if navi.Name != "NAVI" || navi.Score != 2 || !slices.Equal(navi.PlayerSteamIDs, []uint64{1}) {
+		t.Errorf("unexpected team %+v", navi)
+	}
+	if vitality.Name != "Vitality" || vitality.Score != 0 || !slices.Equal(vitality.PlayerSteamIDs, []uint64{2}) {
+		t.Errorf("unexpected team %+v", vitality)
+	}
+	if series.Winner != navi {
+		t.Errorf("expected NAVI to win the series, got %+v", series.Winner)
+	}
+
+	if len(series.Players) != 2 {
+		t.Fatalf("expected 2 players, got %d", len(series.Players))
+	}
+	player := series.Players[0]
+	if player.SteamID64 != 1 || player.TeamName != "NAVI" || player.MapCount != 2 || player.RoundCount != 4 ||
+		player.KillCount != 2 || player.AverageDamagePerRound != 50 || player.KAST != 100 {
+		t.Errorf("unexpected player stats %+v", player)
+	}
+	// Stats are the same on each map, the series rating is the rating of a map.
+	mapRating := series.Matches[0].PlayersBySteamID[1].HltvRating2()
+	if player.HltvRating2 != mapRating {
+		t.Errorf("expected rating %f, got %f", mapRating, player.HltvRating2)
+	}
+	if victim := series.Players[1]; victim.DeathCount != 2 || victim.KAST != 50 {
+		t.Errorf("unexpected player stats %+v", victim)
+	}
+}
+
+// buildSource1Demo returns a fake CSGO demo, only its header is valid.
+func buildSource1Demo() []byte {
+	var buffer bytes.Buffer
+	buffer.WriteString("HL2DEMO\x00")
+	binary.Write(&buffer, binary.LittleEndian, int32(4))     // demo protocol
+	binary.Write(&buffer, binary.LittleEndian, int32(13881)) // network protocol
+	for _, value := range []string{"server", "GOTV Demo", "de_dust2", "csgo"} {
+		field := make([]byte, 260)
+		copy(field, value)
+		buffer.Write(field)
+	}
+	binary.Write(&buffer, binary.LittleEndian, float32(2400)) // duration
+	binary.Write(&buffer, binary.LittleEndian, int32(153600)) // ticks
+	binary.Write(&buffer, binary.LittleEndian, int32(76800))  // frames
+	binary.Write(&buffer, binary.LittleEndian, int32(1024))   // signon length
+	buffer.Write(bytes.Repeat([]byte{0xab}, 64*1024))
+
+	return buffer.Bytes()
+}
+
+func TestAnalyzeSeriesFromCache(t *testing.T) {
+	folderPath := t.TempDir()
+	demoPath := filepath.Join(folderPath, "match.dem")
+	if err := os.WriteFile(demoPath, buildSource1Demo(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var compressedDemo bytes.Buffer
+	writer := gzip.NewWriter(&compressedDemo)
+	writer.Write(buildSource1Demo())
+	writer.Close()
+	compressedDemoPath := demoPath + ".gz"
+	if err := os.WriteFile(compressedDemoPath, compressedDemo.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the header of the demo is valid, the match is read from the cache.
+	infos, err := InspectDemos(demoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := newAnalysisCache(t.TempDir(), AnalyzeDemoOptions{})
+	key, err := cache.key(infos[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	match := newSeriesTestMatch(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), "NAVI", "Vitality", constants.TeamLetterA, 1, 2)
+	match.Checksum = infos[0].Checksum
+	if err = cache.write(key, match); err != nil {
+		t.Fatal(err)
+	}
+
+	// The compressed copy of the demo has the same checksum, the map is counted once.
+	series, err := AnalyzeSeries([]string{demoPath, compressedDemoPath}, AnalyzeSeriesOptions{CacheFolderPath: cache.folderPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series.Matches) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(series.Matches))
+	}
+	if series.Teams[0].Score != 1 || series.Players[0].MapCount != 1 || series.Players[0].KillCount != 1 {
+		t.Errorf("the map has been counted several times: %+v %+v", series.Teams[0], series.Players[0])
+	}
+}
@@ -1,8 +1,7 @@
 package api
 
 import (
-	"fmt"
-
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
 	"github.com/markus-wa/demoinfocs-golang/v4/pkg/demoinfocs/common"
 	"github.com/markus-wa/demoinfocs-golang/v4/pkg/demoinfocs/events"
 )
@@ -30,13 +29,13 @@ type SmokeStart struct {
 func newSmokeStartFromGameEvent(analyzer *Analyzer, event events.SmokeStart) *SmokeStart {
 	grenade := event.Grenade
 	if grenade == nil {
-		fmt.Println("Grenade nil in smoke start event")
+		analyzer.addDiagnostic(constants.DiagnosticSeverityWarning, constants.DiagnosticCodeMissingGrenade, "Grenade nil in smoke start event")
 		return nil
 	}
 
 	thrower := event.Thrower
 	if thrower == nil {
-		fmt.Println("Thrower nil in smoke start event")
+		analyzer.addDiagnostic(constants.DiagnosticSeverityWarning, constants.DiagnosticCodeMissingThrower, "Thrower nil in smoke start event")
 		return nil
 	}
 
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/akiver/cs-demo-analyzer/pkg/api"
+)
+
+// batchResult is the outcome of the analysis of one demo when analyzing a whole folder.
+type batchResult struct {
+	demoPath string
+	err      error
+}
+
+// findDemoPaths returns the path of all files inside the folder and its sub-folders whose name matches the pattern.
+func findDemoPaths(folderPath string, pattern string) ([]string, error) {
+	var demoPaths []string
+	err := filepath.WalkDir(folderPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		matched, err := filepath.Match(pattern, entry.Name())
+		if err != nil {
+			return err
+		}
+		if matched {
+			demoPaths = append(demoPaths, path)
+		}
+
+		return nil
+	})
+
+	return demoPaths, err
+}
+
+// demoOutputPath returns the output folder of a demo of the demos folder, the folder structure is mirrored in the
+// output folder to not overwrite the export of demos with the same name located in different sub-folders.
+// A SQLite database shared by all demos is returned as is.
+func demoOutputPath(cli *cliArgs, demoPath string) (string, error) {
+	stat, err := os.Stat(cli.outputPath)
+	if err != nil || !stat.IsDir() {
+		return cli.outputPath, nil
+	}
+
+	relativePath, err := filepath.Rel(cli.demoDir, filepath.Dir(demoPath))
+	if err != nil {
+		return "", err
+	}
+
+	outputPath := filepath.Join(cli.outputPath, relativePath)
+	if err := os.MkdirAll(outputPath, 0755); err != nil {
+		return "", err
+	}
+
+	return outputPath, nil
+}
+
+func analyzeAndExportDemo(demoPath string, cli *cliArgs) (err error) {
+	// The parser may panic with corrupted demos, recover to not stop the analysis of the other demos.
+	defer func() {
+		if r := recover(); r != nil {
+			if recoveredErr, ok := r.(error); ok {
+				err = recoveredErr
+			} else {
+				err = fmt.Errorf("%v", r)
+			}
+		}
+	}()
+
+	outputPath, err := demoOutputPath(cli, demoPath)
+	if err != nil {
+		return err
+	}
+
+	return api.AnalyzeAndExportDemo(demoPath, outputPath, cli.exportOptions())
+}
+
+// runBatch analyzes the demos of the folder with cli.concurrency workers, analyze is replaced in tests.
+// The result of each demo is printed on stderr to not be mixed with the progress lines printed on stdout.
+func runBatch(cli *cliArgs, analyze func(demoPath string, cli *cliArgs) error) int {
+	demoPaths, err := findDemoPaths(cli.demoDir, cli.pattern)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return exitCodeError
+	}
+
+	if len(demoPaths) == 0 {
+		fmt.Fprintf(os.Stderr, "no demos matching %q found in %q\n", cli.pattern, cli.demoDir)
+		return exitCodeError
+	}
+
+	results := make([]batchResult, len(demoPaths))
+	demoIndexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < cli.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range demoIndexes {
+				demoPath := demoPaths[index]
+				results[index] = batchResult{
+					demoPath: demoPath,
+					err:      analyze(demoPath, cli),
+				}
+			}
+		}()
+	}
+
+	for index := range demoPaths {
+		demoIndexes <- index
+	}
+	close(demoIndexes)
+	wg.Wait()
+
+	failedCount := 0
+	for _, result := range results {
+		if result.err != nil {
+			failedCount++
+			fmt.Fprintf(os.Stderr, "FAILED %s: %v\n", result.demoPath, result.err)
+		} else {
+			fmt.Fprintf(os.Stderr, "OK     %s\n", result.demoPath)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "%d demos analyzed, %d succeeded, %d failed\n", len(results), len(results)-failedCount, failedCount)
+
+	if failedCount > 0 {
+		return exitCodeError
+	}
+
+	return 0
+}
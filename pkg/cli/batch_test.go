@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunBatch(t *testing.T) {
+	demosFolderPath := t.TempDir()
+	outputFolderPath := t.TempDir()
+	demoPaths := []string{
+		filepath.Join(demosFolderPath, "day1", "match.dem"),
+		filepath.Join(demosFolderPath, "day2", "match.dem"),
+		filepath.Join(demosFolderPath, "corrupted.dem"),
+		filepath.Join(demosFolderPath, "other.dem"),
+	}
+	for _, demoPath := range demoPaths {
+		if err := os.MkdirAll(filepath.Dir(demoPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(demoPath, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cli := &cliArgs{
+		demoDir:     demosFolderPath,
+		pattern:     "*.dem",
+		concurrency: 2,
+		outputPath:  outputFolderPath,
+	}
+	var mutex sync.Mutex
+	var runningCount, maxRunningCount int
+	outputPaths := make(map[string]string)
+	exitCode := runBatch(cli, func(demoPath string, cli *cliArgs) error {
+		mutex.Lock()
+		runningCount++
+		maxRunningCount = max(maxRunningCount, runningCount)
+		mutex.Unlock()
+		defer func() {
+			mutex.Lock()
+			runningCount--
+			mutex.Unlock()
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		outputPath, err := demoOutputPath(cli, demoPath)
+		if err != nil {
+			return err
+		}
+		mutex.Lock()
+		outputPaths[demoPath] = outputPath
+		mutex.Unlock()
+
+		if filepath.Base(demoPath) == "corrupted.dem" {
+			return errors.New("corrupted demo")
+		}
+
+		return nil
+	})
+
+	if exitCode != exitCodeError {
+		t.Errorf("expected exit code %d when a demo failed, got %d", exitCodeError, exitCode)
+	}
+	if len(outputPaths) != len(demoPaths) {
+		t.Fatalf("expected %d demos analyzed, got %d", len(demoPaths), len(outputPaths))
+	}
+	if maxRunningCount > 2 {
+		t.Errorf("expected at most 2 demos analyzed at the same time, got %d", maxRunningCount)
+	}
+
+	expectedOutputPaths := []string{
+		filepath.Join(outputFolderPath, "day1"),
+		filepath.Join(outputFolderPath, "day2"),
+		outputFolderPath,
+		outputFolderPath,
+	}
+	for index, demoPath := range demoPaths {
+		if outputPaths[demoPath] != expectedOutputPaths[index] {
+			t.Errorf("expected %s to be exported into %s, got %s", demoPath, expectedOutputPaths[index], outputPaths[demoPath])
+		}
+	}
+
+	exitCode = runBatch(cli, func(demoPath string, cli *cliArgs) error {
+		return nil
+	})
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0 when all demos succeeded, got %d", exitCode)
+	}
+
+	var analyzedDemoPaths []string
+	cli.pattern = "missing*.dem"
+	exitCode = runBatch(cli, func(demoPath string, cli *cliArgs) error {
+		analyzedDemoPaths = append(analyzedDemoPaths, demoPath)
+		return nil
+	})
+	if exitCode != exitCodeError || len(analyzedDemoPaths) != 0 {
+		t.Errorf("expected exit code %d without demos, got %d", exitCodeError, exitCode)
+	}
+}
+
+func TestValidateBatchOutput(t *testing.T) {
+	outputFilePath := filepath.Join(t.TempDir(), "output.json")
+	if err := os.WriteFile(outputFilePath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cli := &cliArgs{demoDir: t.TempDir(), pattern: "*.dem", concurrency: 1, outputPath: outputFilePath, format: "json"}
+	if err := cli.validateArgs(); err == nil {
+		t.Error("expected an error when -output is a file with -demo-dir")
+	}
+
+	// SQLite databases may be shared by all demos.
+	cli.format = "sqlite"
+	if err := cli.validateArgs(); err != nil {
+		t.Errorf("unexpected error with a SQLite database: %v", err)
+	}
+}
@@ -1,10 +1,17 @@
 package cli
 
 import (
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/akiver/cs-demo-analyzer/pkg/api"
 	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
@@ -12,16 +19,66 @@ import (
 
 type cliArgs struct {
 	demoPath         string
+	demoDir          string
+	pattern          string
+	concurrency      int
 	includePositions bool
+	positionSampling api.PositionSampling
 	source           string
 	outputPath       string
 	format           string
 	minifyJSON       bool
+	compression      string
+	progress         string
+	errorFormat      string
+	contentHash      bool
+	include          string
+	exclude          string
+	cacheDir         string
+	noCache          bool
+	clearCache       bool
+	series           bool
 }
 
 func (cli *cliArgs) validateArgs() error {
-	if cli.demoPath == "" {
-		return errors.New("demo file path required, example: -demo-path path/to/demo.dem")
+	if cli.clearCache && cli.cacheDir == "" {
+		return errors.New("-clear-cache requires -cache-dir")
+	}
+
+	// The cache can be cleared without analyzing demos.
+	if cli.clearCache && cli.demoPath == "" && cli.demoDir == "" {
+		return nil
+	}
+
+	if cli.demoPath == "" && cli.demoDir == "" {
+		return errors.New("demo file path or demos folder required, example: -demo-path path/to/demo.dem or -demo-dir path/to/demos")
+	}
+
+	if cli.demoPath != "" && cli.demoDir != "" {
+		return errors.New("-demo-path and -demo-dir can't be used together")
+	}
+
+	if cli.demoDir != "" {
+		if stat, err := os.Stat(cli.demoDir); err != nil || !stat.IsDir() {
+			return fmt.Errorf("demos folder %q not found", cli.demoDir)
+		}
+
+		if _, err := filepath.Match(cli.pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", cli.pattern, err)
+		}
+
+		if cli.concurrency < 1 {
+			return errors.New("concurrency must be greater than 0")
+		}
+
+		// Demos are exported into the folder, except with SQLite where the database may be shared by all demos and with
+		// -series that exports a single file.
+		isSharedOutput := cli.series || cli.format == string(constants.ExportFormatSQLite)
+		if cli.outputPath != "" && cli.outputPath != api.StdoutOutputPath && !isSharedOutput {
+			if stat, err := os.Stat(cli.outputPath); err != nil || !stat.IsDir() {
+				return fmt.Errorf("output folder %q not found, -output must be a folder when -demo-dir is set", cli.outputPath)
+			}
+		}
 	}
 
 	if cli.outputPath == "" {
@@ -35,6 +92,47 @@ func (cli *cliArgs) validateArgs() error {
 		}
 	}
 
+	if cli.series && cli.format != string(constants.ExportFormatJSON) {
+		return errors.New("-series exports the series as JSON, -format must be json")
+	}
+
+	if cli.outputPath == api.StdoutOutputPath {
+		if cli.demoDir != "" && !cli.series {
+			return errors.New("-output - can't be used with -demo-dir")
+		}
+
+		if err := api.ValidateStdoutExportFormat(constants.ExportFormat(cli.format)); err != nil {
+			return err
+		}
+	}
+
+	if err := api.ValidateCompression(constants.Compression(cli.compression), constants.ExportFormat(cli.format)); err != nil {
+		return err
+	}
+
+	for _, category := range append(parseEventCategories(cli.include), parseEventCategories(cli.exclude)...) {
+		err := api.ValidateEventCategory(category)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := api.ValidatePositionSampling(cli.positionSampling); err != nil {
+		return err
+	}
+
+	if cli.positionSampling != (api.PositionSampling{}) && !cli.collectsPositions() {
+		return errors.New("-positions-* options require -positions or positions in -include")
+	}
+
+	if cli.progress != "" && cli.progress != "json" {
+		return fmt.Errorf("invalid progress format %q, valid values: [json]", cli.progress)
+	}
+
+	if cli.errorFormat != "" && cli.errorFormat != errorFormatJSON {
+		return fmt.Errorf("invalid error format %q, valid values: [json]", cli.errorFormat)
+	}
+
 	if cli.source != "" {
 		err := api.ValidateDemoSource(constants.DemoSource(cli.source))
 		if err != nil {
@@ -45,21 +143,61 @@ func (cli *cliArgs) validateArgs() error {
 	return nil
 }
 
-func (cli *cliArgs) fromArgs(args []string) error {
-	fs := flag.NewFlagSet("csda", flag.ContinueOnError)
-	fs.StringVar(&cli.demoPath, "demo-path", "", "Demo file path (mandatory)")
-	fs.StringVar(&cli.outputPath, "output", "", "Output folder or file path, must be a folder when exporting to CSV (mandatory)")
+// addExportFlags adds the flags of the analysis and export options, they are shared with the watch command.
+func (cli *cliArgs) addExportFlags(fs *flag.FlagSet) {
 	fs.StringVar(&cli.format, "format", "csv", "Export format, valid values: "+api.FormatValidExportFormats())
 	fs.StringVar(&cli.source, "source", "", "Force demo's source, valid values: "+api.FormatValidDemoSources())
 	fs.BoolVar(&cli.includePositions, "positions", false, "Include entities (players, grenades...) positions (default false)")
+	fs.IntVar(&cli.positionSampling.EveryTicks, "positions-every-ticks", 0, "Record positions every N ticks instead of every frame")
+	fs.Float64Var(&cli.positionSampling.Frequency, "positions-hz", 0, "Record positions N times per second of game time, it takes precedence over -positions-every-ticks")
+	fs.IntVar(&cli.positionSampling.StartRound, "positions-start-round", 0, "Record positions starting from this round number")
+	fs.IntVar(&cli.positionSampling.EndRound, "positions-end-round", 0, "Record positions until this round number (inclusive)")
+	fs.IntVar(&cli.positionSampling.StartTick, "positions-start-tick", 0, "Record positions starting from this tick")
+	fs.IntVar(&cli.positionSampling.EndTick, "positions-end-tick", 0, "Record positions until this tick (inclusive)")
+	fs.BoolVar(&cli.positionSampling.AliveOnly, "positions-alive-only", false, "Record only the positions of alive players (default false)")
+	fs.StringVar(&cli.include, "include", "", "Comma-separated list of events categories to collect, all categories except positions if not set, valid values: "+api.FormatValidEventCategories())
+	fs.StringVar(&cli.exclude, "exclude", "", "Comma-separated list of events categories to not collect, valid values: "+api.FormatValidEventCategories())
+	fs.BoolVar(&cli.contentHash, "content-hash", false, "Compute the SHA-256 hash of the whole demo content, it's exported with the match (default false)")
 	fs.BoolVar(&cli.minifyJSON, "minify", false, "Minify JSON file, it has effect only when -format is set to json")
+	fs.StringVar(&cli.compression, "compress", "", "Compress the export files, it has effect only when -format is set to csv, json or csdm, valid values: "+api.FormatValidCompressions())
+	fs.StringVar(&cli.cacheDir, "cache-dir", "", "Folder of the analysis cache, demos already analyzed with the same csda version and options are not analyzed again, not used with -format ndjson")
+	fs.BoolVar(&cli.noCache, "no-cache", false, "Don't read nor write the analysis cache even if -cache-dir is set (default false)")
+}
+
+func (cli *cliArgs) fromArgs(args []string) error {
+	fs := flag.NewFlagSet("csda", flag.ContinueOnError)
+	fs.StringVar(&cli.demoPath, "demo-path", "", "Demo file path (mandatory if -demo-dir is not set)")
+	fs.StringVar(&cli.demoDir, "demo-dir", "", "Folder containing the demos to analyze, sub-folders are included (mandatory if -demo-path is not set)")
+	fs.StringVar(&cli.pattern, "pattern", "*.dem", "Pattern that demo file names must match, it has effect only when -demo-dir is set")
+	fs.IntVar(&cli.concurrency, "concurrency", runtime.NumCPU(), "Number of demos analyzed at the same time, it has effect only when -demo-dir is set")
+	fs.StringVar(&cli.outputPath, "output", "", "Output folder or file path, must be a folder when exporting to CSV, Parquet or PostgreSQL or when -demo-dir is set, except for SQLite databases that may be shared, - writes the export on stdout, CSV files are written as a tar stream (mandatory)")
+	cli.addExportFlags(fs)
+	fs.StringVar(&cli.progress, "progress", "", "Print the analysis progress on stdout, valid values: [json] (one JSON object per line)")
+	fs.StringVar(&cli.errorFormat, "error-format", "", "Print the error that stopped the analysis on stderr as a JSON object, valid values: [json]")
+	fs.BoolVar(&cli.series, "series", false, "Analyze the demos of -demo-dir, or the ones of the -demo-path archive, as the maps of a series and export a JSON file with the stats of the players across the maps and the teams score (default false)")
+	fs.BoolVar(&cli.clearCache, "clear-cache", false, "Remove the entries of the analysis cache located in -cache-dir before analyzing, demos are optional (default false)")
 
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 
+	// Series are exported only as JSON, it's the default format in this case.
+	if cli.series {
+		isFormatSet := false
+		fs.Visit(func(f *flag.Flag) {
+			isFormatSet = isFormatSet || f.Name == "format"
+		})
+		if !isFormatSet {
+			cli.format = string(constants.ExportFormatJSON)
+		}
+	}
+
 	if err := cli.validateArgs(); err != nil {
-		fmt.Fprintf(os.Stderr, "%v\n", err)
+		printError(cli.errorFormat, jsonError{
+			Code:     errorCodeInvalidArgs,
+			Message:  err.Error(),
+			ExitCode: exitCodeInvalidArgs,
+		})
 		fs.Usage()
 		return err
 	}
@@ -67,23 +205,131 @@ func (cli *cliArgs) fromArgs(args []string) error {
 	return nil
 }
 
+func (cli *cliArgs) exportOptions() api.AnalyzeAndExportDemoOptions {
+	options := api.AnalyzeAndExportDemoOptions{
+		IncludePositions:   cli.includePositions,
+		PositionSampling:   cli.positionSampling,
+		Source:             constants.DemoSource(cli.source),
+		Format:             constants.ExportFormat(cli.format),
+		MinifyJSON:         cli.minifyJSON,
+		Compression:        constants.Compression(cli.compression),
+		IncludeEvents:      parseEventCategories(cli.include),
+		ExcludeEvents:      parseEventCategories(cli.exclude),
+		Logger:             stderrLogger{},
+		ComputeContentHash: cli.contentHash,
+	}
+
+	if !cli.noCache {
+		options.CacheFolderPath = cli.cacheDir
+	}
+
+	if cli.progress == "json" {
+		// Progress lines would corrupt the export when it's written on stdout.
+		progressWriter := os.Stdout
+		if cli.outputPath == api.StdoutOutputPath {
+			progressWriter = os.Stderr
+		}
+		options.Progress = func(progress api.AnalyzeProgress) {
+			printProgress(progressWriter, progress)
+		}
+	}
+
+	return options
+}
+
+// collectsPositions returns true if the positions are collected, see api.AnalyzeDemoOptions.ExcludeEvents.
+func (cli *cliArgs) collectsPositions() bool {
+	if slices.Contains(parseEventCategories(cli.exclude), constants.EventCategoryPositions) {
+		return false
+	}
+
+	return cli.includePositions || slices.Contains(parseEventCategories(cli.include), constants.EventCategoryPositions)
+}
+
+func parseEventCategories(value string) []constants.EventCategory {
+	var categories []constants.EventCategory
+	for _, category := range strings.Split(value, ",") {
+		category = strings.TrimSpace(category)
+		if category != "" {
+			categories = append(categories, constants.EventCategory(category))
+		}
+	}
+
+	return categories
+}
+
+// stderrLogger prints the diagnostics detected during the analysis on stderr.
+type stderrLogger struct{}
+
+func (stderrLogger) LogDiagnostic(diagnostic api.Diagnostic) {
+	fmt.Fprintf(
+		os.Stderr,
+		"%s %s (tick %d, round %d): %s\n",
+		diagnostic.Severity,
+		diagnostic.Code,
+		diagnostic.Tick,
+		diagnostic.RoundNumber,
+		diagnostic.Message,
+	)
+}
+
+var progressMutex sync.Mutex
+
+// printProgress prints the progress as a JSON line, demos may be analyzed concurrently in batch mode.
+func printProgress(writer io.Writer, progress api.AnalyzeProgress) {
+	line, err := json.Marshal(progress)
+	if err != nil {
+		return
+	}
+
+	progressMutex.Lock()
+	defer progressMutex.Unlock()
+	fmt.Fprintln(writer, string(line))
+}
+
 func Run(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "schema":
+			return runSchema(args[1:])
+		case "info":
+			return runInfo(args[1:])
+		case "serve":
+			return runServe(args[1:])
+		case "grpc":
+			return runGRPC(args[1:])
+		case "watch":
+			return runWatch(args[1:])
+		}
+	}
+
 	var cli cliArgs
 	err := cli.fromArgs(args)
 	if err != nil {
-		return 2
+		return exitCodeInvalidArgs
+	}
+
+	if cli.clearCache {
+		if err := api.ClearCache(cli.cacheDir); err != nil {
+			return printError(cli.errorFormat, newJSONError(err))
+		}
+		if cli.demoPath == "" && cli.demoDir == "" {
+			return 0
+		}
+	}
+
+	if cli.series {
+		return runSeries(&cli)
+	}
+
+	if cli.demoDir != "" {
+		return runBatch(&cli, analyzeAndExportDemo)
 	}
 
-	err = api.AnalyzeAndExportDemo(cli.demoPath, cli.outputPath, api.AnalyzeAndExportDemoOptions{
-		IncludePositions: cli.includePositions,
-		Source:           constants.DemoSource(cli.source),
-		Format:           constants.ExportFormat(cli.format),
-		MinifyJSON:       cli.minifyJSON,
-	})
+	err = api.AnalyzeAndExportDemo(cli.demoPath, cli.outputPath, cli.exportOptions())
 
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%v\n", err)
-		return 1
+		return printError(cli.errorFormat, newJSONError(err))
 	}
 
 	return 0
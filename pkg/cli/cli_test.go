@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akiver/cs-demo-analyzer/pkg/api"
+)
+
+func TestValidatePositionSamplingArgs(t *testing.T) {
+	demoPath := filepath.Join(t.TempDir(), "match.dem")
+	if err := os.WriteFile(demoPath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name             string
+		includePositions bool
+		include          string
+		exclude          string
+		sampling         api.PositionSampling
+		isInvalid        bool
+	}{
+		{"no sampling without positions", false, "", "", api.PositionSampling{}, false},
+		{"sampling with -positions", true, "", "", api.PositionSampling{EveryTicks: 8}, false},
+		{"sampling with positions included", false, "kills,positions", "", api.PositionSampling{Frequency: 16}, false},
+		{"sampling without positions", false, "", "", api.PositionSampling{EveryTicks: 8}, true},
+		{"alive only without positions", false, "kills", "", api.PositionSampling{AliveOnly: true}, true},
+		{"sampling with positions excluded", true, "", "positions", api.PositionSampling{StartRound: 2}, true},
+	}
+
+	for _, test := range tests {
+		cli := &cliArgs{
+			demoPath:         demoPath,
+			outputPath:       t.TempDir(),
+			format:           "json",
+			includePositions: test.includePositions,
+			include:          test.include,
+			exclude:          test.exclude,
+			positionSampling: test.sampling,
+		}
+		err := cli.validateArgs()
+		if (err != nil) != test.isInvalid {
+			t.Errorf("%s: expected invalid %v, got error %v", test.name, test.isInvalid, err)
+		}
+	}
+}
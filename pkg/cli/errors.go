@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/akiver/cs-demo-analyzer/pkg/api"
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+)
+
+const (
+	exitCodeError                       = 1
+	exitCodeInvalidArgs                 = 2
+	exitCodeUnknownSource               = 3
+	exitCodeUnsupportedDemo             = 4
+	exitCodeMissingGameEventDescriptors = 5
+	errorFormatJSON                     = "json"
+	errorCodeInvalidArgs                = "InvalidArgs"
+)
+
+// jsonError is the error printed on stderr when -error-format is set to json.
+type jsonError struct {
+	Code     string               `json:"code"`
+	Message  string               `json:"message"`
+	ExitCode int                  `json:"exitCode"`
+	Source   constants.DemoSource `json:"source,omitempty"`
+	Game     constants.Game       `json:"game,omitempty"`
+}
+
+// newJSONError returns the code and the exit code of an error returned by the analysis.
+func newJSONError(err error) jsonError {
+	jsonErr := jsonError{
+		Code:     api.ErrorCode(err),
+		Message:  err.Error(),
+		ExitCode: exitCodeError,
+	}
+
+	var unsupportedDemoErr *api.UnsupportedDemoError
+	switch {
+	case errors.Is(err, api.ErrUnknownSource):
+		jsonErr.ExitCode = exitCodeUnknownSource
+	case errors.Is(err, api.ErrMissingGameEventDescriptors):
+		jsonErr.ExitCode = exitCodeMissingGameEventDescriptors
+	case errors.As(err, &unsupportedDemoErr):
+		jsonErr.ExitCode = exitCodeUnsupportedDemo
+		jsonErr.Source = unsupportedDemoErr.Source
+		jsonErr.Game = unsupportedDemoErr.Game
+	}
+
+	return jsonErr
+}
+
+// printError prints the error on stderr using the given format and returns the exit code associated with the error.
+func printError(format string, jsonErr jsonError) int {
+	if format == errorFormatJSON {
+		line, err := json.Marshal(jsonErr)
+		if err == nil {
+			fmt.Fprintln(os.Stderr, string(line))
+			return jsonErr.ExitCode
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "%s\n", jsonErr.Message)
+
+	return jsonErr.ExitCode
+}
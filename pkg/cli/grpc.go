@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/akiver/cs-demo-analyzer/pkg/grpcserver"
+)
+
+// runGRPC starts a gRPC server that analyzes demos until the process is interrupted.
+func runGRPC(args []string) int {
+	var options grpcserver.Options
+	var maxUploadSizeMB int64
+	fs := flag.NewFlagSet("csda grpc", flag.ContinueOnError)
+	address := fs.String("address", "127.0.0.1:4001", "Address the server listens on")
+	fs.IntVar(&options.Concurrency, "concurrency", runtime.NumCPU(), "Number of demos analyzed at the same time")
+	fs.StringVar(&options.DemosFolderPath, "demo-dir", "", "Folder containing the demos that can be analyzed from their path, only uploads are allowed if not set")
+	fs.Int64Var(&maxUploadSizeMB, "max-upload-size", grpcserver.DefaultMaxUploadSize>>20, "Maximum size of uploaded demos in MB")
+	if err := fs.Parse(args); err != nil {
+		return exitCodeInvalidArgs
+	}
+
+	if options.DemosFolderPath != "" {
+		if stat, err := os.Stat(options.DemosFolderPath); err != nil || !stat.IsDir() {
+			fmt.Fprintf(os.Stderr, "demos folder %q not found\n", options.DemosFolderPath)
+			return exitCodeInvalidArgs
+		}
+	}
+	options.MaxUploadSize = maxUploadSizeMB << 20
+
+	listener, err := net.Listen("tcp", *address)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return exitCodeError
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := grpcserver.New(options)
+	serveErr := make(chan error, 1)
+	go func() {
+		fmt.Fprintf(os.Stderr, "listening on %s\n", listener.Addr())
+		serveErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err = <-serveErr:
+	case <-ctx.Done():
+		// Running analyses are cancelled if they don't finish in time.
+		stopped := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(10 * time.Second):
+			server.Stop()
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return exitCodeError
+	}
+
+	return 0
+}
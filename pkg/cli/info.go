@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/akiver/cs-demo-analyzer/pkg/api"
+)
+
+// runInfo prints the header of demos without analyzing them, as text or as one JSON object per line.
+func runInfo(args []string) int {
+	fs := flag.NewFlagSet("csda info", flag.ContinueOnError)
+	format := fs.String("format", "text", "Output format, valid values: [text,json] (one JSON object per line)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: csda info [-format text|json] demo [demo...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitCodeInvalidArgs
+	}
+
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "invalid format %q, valid values: [text,json]\n", *format)
+		fs.Usage()
+		return exitCodeInvalidArgs
+	}
+
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "at least one demo file path required, example: csda info path/to/demo.dem")
+		fs.Usage()
+		return exitCodeInvalidArgs
+	}
+
+	exitCode := 0
+	isFirstDemo := true
+	for _, demoPath := range fs.Args() {
+		infos, err := api.InspectDemos(demoPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", demoPath, err)
+			exitCode = exitCodeError
+			continue
+		}
+
+		for _, info := range infos {
+			if *format == "json" {
+				line, err := json.Marshal(info)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "%s: %v\n", demoPath, err)
+					exitCode = exitCodeError
+					continue
+				}
+				fmt.Println(string(line))
+				continue
+			}
+
+			if !isFirstDemo {
+				fmt.Println()
+			}
+			isFirstDemo = false
+			printDemoInfo(os.Stdout, info)
+		}
+	}
+
+	return exitCode
+}
+
+func printDemoInfo(writer io.Writer, info *api.DemoInfo) {
+	tw := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	defer tw.Flush()
+
+	printField := func(name string, value any) {
+		fmt.Fprintf(tw, "%s:\t%v\n", name, value)
+	}
+
+	printField("Demo", info.DemoFilePath)
+	printField("Checksum", info.Checksum)
+	printField("Game", info.Game)
+	printField("Source", info.Source)
+	printField("Type", info.Type)
+	printField("Map", info.MapName)
+	printField("Server", info.ServerName)
+	printField("Client", info.ClientName)
+	printField("Date", info.Date.Format(time.RFC3339))
+	printField("Size", info.Size)
+	printField("Network protocol", info.NetworkProtocol)
+	if info.BuildNumber > 0 {
+		printField("Build number", info.BuildNumber)
+	}
+	if info.TickCount > 0 {
+		printField("Ticks", info.TickCount)
+		printField("Tickrate", fmt.Sprintf("%.2f", info.TickRate))
+		printField("Duration", info.Duration.Round(time.Second))
+	}
+	if info.ShareCode != "" {
+		printField("Share code", info.ShareCode)
+	}
+	if matchInfo := info.MatchInfo; matchInfo != nil {
+		printField("Match ID", matchInfo.MatchID)
+		printField("Reservation ID", matchInfo.ReservationID)
+		if matchInfo.ServerIP != "" {
+			printField("Server IP", matchInfo.ServerIP)
+		}
+		printField("TV port", matchInfo.TvPort)
+		if len(matchInfo.TeamScores) > 0 {
+			scores := make([]string, 0, len(matchInfo.TeamScores))
+			for _, score := range matchInfo.TeamScores {
+				scores = append(scores, fmt.Sprint(score))
+			}
+			printField("Score", strings.Join(scores, " - "))
+		}
+		if matchInfo.Duration > 0 {
+			printField("Match duration", matchInfo.Duration)
+		}
+	}
+	for _, warning := range info.Warnings {
+		printField("Warning", warning)
+	}
+}
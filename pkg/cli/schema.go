@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/akiver/cs-demo-analyzer/pkg/api"
+)
+
+// runSchema prints the JSON Schema of the JSON export on stdout.
+func runSchema(args []string) int {
+	fs := flag.NewFlagSet("csda schema", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	schema, err := api.JSONSchema()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	_, err = os.Stdout.Write(schema)
+	if err != nil {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/akiver/cs-demo-analyzer/pkg/api"
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+)
+
+// runSeries analyzes the demos of a series, one per map, and exports the series as a JSON file.
+// The demos are the ones of -demo-dir or the ones contained in the -demo-path file, i.e. a zip archive of a BO3.
+func runSeries(cli *cliArgs) int {
+	demoPaths := []string{cli.demoPath}
+	if cli.demoDir != "" {
+		var err error
+		demoPaths, err = findDemoPaths(cli.demoDir, cli.pattern)
+		if err != nil {
+			return printError(cli.errorFormat, newJSONError(err))
+		}
+
+		if len(demoPaths) == 0 {
+			fmt.Fprintf(os.Stderr, "no demos matching %q found in %q\n", cli.pattern, cli.demoDir)
+			return exitCodeError
+		}
+	}
+
+	options := cli.exportOptions()
+	series, err := api.AnalyzeSeries(demoPaths, api.AnalyzeSeriesOptions{
+		IncludePositions:   options.IncludePositions,
+		PositionSampling:   options.PositionSampling,
+		Source:             options.Source,
+		IncludeEvents:      options.IncludeEvents,
+		ExcludeEvents:      options.ExcludeEvents,
+		Progress:           options.Progress,
+		Logger:             options.Logger,
+		ComputeContentHash: options.ComputeContentHash,
+		CacheFolderPath:    options.CacheFolderPath,
+	})
+	if err != nil {
+		return printError(cli.errorFormat, newJSONError(err))
+	}
+
+	err = api.ExportSeriesToJSON(series, cli.outputPath, cli.minifyJSON, constants.Compression(cli.compression))
+	if err != nil {
+		return printError(cli.errorFormat, newJSONError(err))
+	}
+
+	return 0
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/akiver/cs-demo-analyzer/pkg/server"
+)
+
+// runServe starts an HTTP server that analyzes demos until the process is interrupted.
+func runServe(args []string) int {
+	var options server.Options
+	var maxUploadSizeMB int64
+	fs := flag.NewFlagSet("csda serve", flag.ContinueOnError)
+	address := fs.String("address", "127.0.0.1:4000", "Address the server listens on")
+	fs.IntVar(&options.Concurrency, "concurrency", runtime.NumCPU(), "Number of demos analyzed at the same time")
+	fs.IntVar(&options.QueueSize, "queue-size", server.DefaultQueueSize, "Number of demos waiting to be analyzed, requests are rejected when the queue is full")
+	fs.StringVar(&options.DemosFolderPath, "demo-dir", "", "Folder containing the demos that can be analyzed from their path, only uploads are allowed if not set")
+	fs.Int64Var(&maxUploadSizeMB, "max-upload-size", server.DefaultMaxUploadSize>>20, "Maximum size of uploaded demos in MB")
+	fs.DurationVar(&options.JobTTL, "job-ttl", server.DefaultJobTTL, "Duration finished jobs and their export are kept")
+	fs.StringVar(&options.TempFolderPath, "temp-dir", "", "Folder where uploaded demos and exports are written (default: the system temporary folder)")
+	if err := fs.Parse(args); err != nil {
+		return exitCodeInvalidArgs
+	}
+
+	if options.DemosFolderPath != "" {
+		if stat, err := os.Stat(options.DemosFolderPath); err != nil || !stat.IsDir() {
+			fmt.Fprintf(os.Stderr, "demos folder %q not found\n", options.DemosFolderPath)
+			return exitCodeInvalidArgs
+		}
+	}
+	options.MaxUploadSize = maxUploadSizeMB << 20
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	demoServer := server.New(options)
+	httpServer := &http.Server{
+		Addr:    *address,
+		Handler: demoServer,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		fmt.Fprintf(os.Stderr, "listening on http://%s\n", *address)
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	var err error
+	select {
+	case err = <-serveErr:
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		err = httpServer.Shutdown(shutdownCtx)
+	}
+
+	err = errors.Join(err, demoServer.Close())
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return exitCodeError
+	}
+
+	return 0
+}
@@ -0,0 +1,331 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/akiver/cs-demo-analyzer/pkg/api"
+)
+
+// Name of the default ledger file, it's created in the watched folder.
+const defaultLedgerFileName = ".csda-watch.jsonl"
+
+const (
+	ledgerStatusSucceeded = "succeeded"
+	ledgerStatusFailed    = "failed"
+)
+
+// ledgerEntry is a line of the ledger, demos are identified by their checksum to not analyze renamed or copied demos
+// again.
+type ledgerEntry struct {
+	Checksum   string    `json:"checksum"`
+	DemoPath   string    `json:"demoPath"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	AnalyzedAt time.Time `json:"analyzedAt"`
+}
+
+// ledger is the list of demos handled by the watch command, one JSON object per line.
+// Entries are only appended to the file so it stays readable if the process is stopped while writing it.
+type ledger struct {
+	file    *os.File
+	entries map[string]ledgerEntry
+}
+
+func openLedger(path string) (*ledger, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger := &ledger{
+		file:    file,
+		entries: make(map[string]ledgerEntry),
+	}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry ledgerEntry
+		// The last line may be incomplete if the process was killed while writing it, its demo is analyzed again.
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Checksum == "" {
+			continue
+		}
+		// Demos that failed during a previous run are analyzed again, i.e. when a new version of csda fixed the error.
+		if entry.Status == ledgerStatusFailed {
+			delete(ledger.entries, entry.Checksum)
+			continue
+		}
+		ledger.entries[entry.Checksum] = entry
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return ledger, nil
+}
+
+func (ledger *ledger) contains(checksum string) bool {
+	_, ok := ledger.entries[checksum]
+
+	return ok
+}
+
+func (ledger *ledger) add(entry ledgerEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	// A previous line may be incomplete, start on a new line to not merge them.
+	stat, err := ledger.file.Stat()
+	if err != nil {
+		return err
+	}
+	if stat.Size() > 0 {
+		lastByte := make([]byte, 1)
+		if _, err := ledger.file.ReadAt(lastByte, stat.Size()-1); err != nil {
+			return err
+		}
+		if lastByte[0] != '\n' {
+			line = append([]byte{'\n'}, line...)
+		}
+	}
+
+	if _, err := ledger.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	ledger.entries[entry.Checksum] = entry
+
+	return ledger.file.Sync()
+}
+
+func (ledger *ledger) close() error {
+	return ledger.file.Close()
+}
+
+// watchedFile is a file of the watched folder, demos are analyzed once their size and modification time are stable.
+type watchedFile struct {
+	size    int64
+	modTime time.Time
+	// Last time the size or the modification time changed.
+	changedAt time.Time
+	// The file has been analyzed or skipped, it's handled again only if it changes.
+	isHandled bool
+}
+
+type watcher struct {
+	cli        *cliArgs
+	stableFor  time.Duration
+	ledger     *ledger
+	ledgerPath string
+	files      map[string]*watchedFile
+	// Analyzes and exports the demo, replaced in tests.
+	analyze func(ctx context.Context, demoPath string) error
+}
+
+func (watcher *watcher) analyzeAndExportDemo(ctx context.Context, demoPath string) (err error) {
+	// The parser may panic with corrupted demos, recover to not stop watching.
+	defer func() {
+		if r := recover(); r != nil {
+			if recoveredErr, ok := r.(error); ok {
+				err = recoveredErr
+			} else {
+				err = fmt.Errorf("%v", r)
+			}
+		}
+	}()
+
+	// Sub-folders are mirrored in the output folder to not overwrite the export of demos with the same name.
+	outputPath, err := demoOutputPath(watcher.cli, demoPath)
+	if err != nil {
+		return err
+	}
+
+	return api.AnalyzeAndExportDemoContext(ctx, demoPath, outputPath, watcher.cli.exportOptions())
+}
+
+// scan looks for new or modified demos and analyzes the ones whose size has been stable for long enough.
+// Demos that are being written are analyzed during a later scan.
+func (watcher *watcher) scan(ctx context.Context) error {
+	demoPaths, err := findDemoPaths(watcher.cli.demoDir, watcher.cli.pattern)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	files := make(map[string]*watchedFile, len(demoPaths))
+	var readyDemoPaths []string
+	for _, demoPath := range demoPaths {
+		if demoPath == watcher.ledgerPath {
+			continue
+		}
+
+		stat, err := os.Stat(demoPath)
+		if err != nil {
+			// The file has been removed since the folder was read.
+			continue
+		}
+
+		file, ok := watcher.files[demoPath]
+		if !ok || file.size != stat.Size() || !file.modTime.Equal(stat.ModTime()) {
+			file = &watchedFile{
+				size:      stat.Size(),
+				modTime:   stat.ModTime(),
+				changedAt: now,
+			}
+		} else if !file.isHandled && file.size > 0 && now.Sub(file.changedAt) >= watcher.stableFor {
+			readyDemoPaths = append(readyDemoPaths, demoPath)
+		}
+		files[demoPath] = file
+	}
+	watcher.files = files
+
+	for _, demoPath := range readyDemoPaths {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		err := watcher.handleDemo(ctx, demoPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// handleDemo analyzes the demo if it's not in the ledger yet, the returned error is a ledger error.
+// The result is printed on stderr like the results of the batch mode.
+func (watcher *watcher) handleDemo(ctx context.Context, demoPath string) error {
+	infos, err := api.InspectDemos(demoPath)
+	if err != nil {
+		// The file may not be a demo or be incomplete although its size didn't change, it's retried if it changes.
+		fmt.Fprintf(os.Stderr, "FAILED %s: %v\n", demoPath, err)
+		watcher.files[demoPath].isHandled = true
+		return nil
+	}
+
+	var checksums []string
+	for _, info := range infos {
+		if !watcher.ledger.contains(info.Checksum) {
+			checksums = append(checksums, info.Checksum)
+		}
+	}
+
+	if len(checksums) == 0 {
+		fmt.Fprintf(os.Stderr, "SKIP   %s: already analyzed\n", demoPath)
+		watcher.files[demoPath].isHandled = true
+		return nil
+	}
+
+	err = watcher.analyze(ctx, demoPath)
+	// The demo is analyzed again on next start when the analysis has been stopped.
+	var cancelledErr *api.CancelledError
+	if errors.As(err, &cancelledErr) && ctx.Err() != nil {
+		return nil
+	}
+
+	entry := ledgerEntry{
+		DemoPath:   demoPath,
+		Status:     ledgerStatusSucceeded,
+		AnalyzedAt: time.Now(),
+	}
+	if err != nil {
+		entry.Status = ledgerStatusFailed
+		entry.Error = err.Error()
+		fmt.Fprintf(os.Stderr, "FAILED %s: %v\n", demoPath, err)
+	} else {
+		fmt.Fprintf(os.Stderr, "OK     %s\n", demoPath)
+	}
+
+	for _, checksum := range checksums {
+		entry.Checksum = checksum
+		if err := watcher.ledger.add(entry); err != nil {
+			return err
+		}
+	}
+	watcher.files[demoPath].isHandled = true
+
+	return nil
+}
+
+// runWatch analyzes the demos of a folder as soon as they are written until the process is interrupted.
+func runWatch(args []string) int {
+	cli := cliArgs{concurrency: 1}
+	fs := flag.NewFlagSet("csda watch", flag.ContinueOnError)
+	fs.StringVar(&cli.demoDir, "dir", "", "Folder to watch, sub-folders are included (mandatory)")
+	fs.StringVar(&cli.pattern, "pattern", "*.dem", "Pattern that demo file names must match")
+	fs.StringVar(&cli.outputPath, "output", "", "Output folder, or SQLite database that may be shared (mandatory)")
+	interval := fs.Duration("interval", 5*time.Second, "Duration between 2 scans of the folder")
+	stableFor := fs.Duration("stable-for", 10*time.Second, "Duration the size of a demo must not change before it's analyzed")
+	ledgerPath := fs.String("ledger", "", "File containing the checksum of the analyzed demos (default: "+defaultLedgerFileName+" in the watched folder)")
+	cli.addExportFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return exitCodeInvalidArgs
+	}
+
+	var err error
+	switch {
+	case cli.demoDir == "":
+		err = errors.New("folder to watch required, example: -dir path/to/demos")
+	case cli.outputPath == api.StdoutOutputPath:
+		err = errors.New("-output - can't be used with the watch command")
+	case *interval <= 0:
+		err = errors.New("interval must be greater than 0")
+	case *stableFor < 0:
+		err = errors.New("stable-for must be positive")
+	default:
+		err = cli.validateArgs()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		fs.Usage()
+		return exitCodeInvalidArgs
+	}
+
+	if *ledgerPath == "" {
+		*ledgerPath = filepath.Join(cli.demoDir, defaultLedgerFileName)
+	}
+	ledger, err := openLedger(*ledgerPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return exitCodeError
+	}
+	defer ledger.close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	watcher := &watcher{
+		cli:        &cli,
+		stableFor:  *stableFor,
+		ledger:     ledger,
+		ledgerPath: filepath.Clean(*ledgerPath),
+	}
+	watcher.analyze = watcher.analyzeAndExportDemo
+
+	fmt.Fprintf(os.Stderr, "watching %s\n", cli.demoDir)
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		if err := watcher.scan(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return exitCodeError
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// buildSource1Demo returns a fake CSGO demo, only its header is valid.
+func buildSource1Demo(mapName string) []byte {
+	var buffer bytes.Buffer
+	buffer.WriteString("HL2DEMO\x00")
+	binary.Write(&buffer, binary.LittleEndian, int32(4))     // demo protocol
+	binary.Write(&buffer, binary.LittleEndian, int32(13881)) // network protocol
+	for _, value := range []string{"server", "GOTV Demo", mapName, "csgo"} {
+		field := make([]byte, 260)
+		copy(field, value)
+		buffer.Write(field)
+	}
+	binary.Write(&buffer, binary.LittleEndian, float32(2400)) // duration
+	binary.Write(&buffer, binary.LittleEndian, int32(153600)) // ticks
+	binary.Write(&buffer, binary.LittleEndian, int32(76800))  // frames
+	binary.Write(&buffer, binary.LittleEndian, int32(1024))   // signon length
+	buffer.Write(bytes.Repeat([]byte{0xab}, 1024))
+
+	return buffer.Bytes()
+}
+
+func newTestWatcher(t *testing.T, folderPath string, analyzedDemoPaths *[]string) *watcher {
+	t.Helper()
+
+	ledgerPath := filepath.Join(folderPath, defaultLedgerFileName)
+	ledger, err := openLedger(ledgerPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		ledger.close()
+	})
+
+	return &watcher{
+		cli:        &cliArgs{demoDir: folderPath, pattern: "*"},
+		ledger:     ledger,
+		ledgerPath: ledgerPath,
+		analyze: func(ctx context.Context, demoPath string) error {
+			*analyzedDemoPaths = append(*analyzedDemoPaths, filepath.Base(demoPath))
+			return nil
+		},
+	}
+}
+
+func TestWatch(t *testing.T) {
+	folderPath := t.TempDir()
+	for name, content := range map[string][]byte{
+		"match.dem": buildSource1Demo("de_dust2"),
+		// Copy of match.dem, it has the same checksum.
+		"copy.dem": buildSource1Demo("de_dust2"),
+		// Still being written.
+		"recording.dem": buildSource1Demo("de_inferno")[:512],
+	} {
+		if err := os.WriteFile(filepath.Join(folderPath, name), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var analyzedDemoPaths []string
+	watcher := newTestWatcher(t, folderPath, &analyzedDemoPaths)
+	ctx := context.Background()
+	// Sizes are known only after the first scan.
+	if err := watcher.scan(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(analyzedDemoPaths) != 0 {
+		t.Fatalf("demos analyzed before their size was known: %v", analyzedDemoPaths)
+	}
+
+	err := os.WriteFile(filepath.Join(folderPath, "recording.dem"), buildSource1Demo("de_inferno"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := watcher.scan(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(analyzedDemoPaths, []string{"copy.dem"}) {
+		t.Fatalf("expected only copy.dem to be analyzed, got %v", analyzedDemoPaths)
+	}
+
+	if err := watcher.scan(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(analyzedDemoPaths, []string{"copy.dem", "recording.dem"}) {
+		t.Fatalf("expected recording.dem to be analyzed once its size is stable, got %v", analyzedDemoPaths)
+	}
+
+	// Simulate a process killed while writing the ledger.
+	ledgerFile, err := os.OpenFile(filepath.Join(folderPath, defaultLedgerFileName), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ledgerFile.WriteString(`{"checksum":"`)
+	ledgerFile.Close()
+
+	if err := os.WriteFile(filepath.Join(folderPath, "new.dem"), buildSource1Demo("de_nuke"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	analyzedDemoPaths = nil
+	watcher = newTestWatcher(t, folderPath, &analyzedDemoPaths)
+	for range 2 {
+		if err := watcher.scan(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !slices.Equal(analyzedDemoPaths, []string{"new.dem"}) {
+		t.Fatalf("expected only new.dem to be analyzed after a restart, got %v", analyzedDemoPaths)
+	}
+
+	ledger, err := openLedger(filepath.Join(folderPath, defaultLedgerFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ledger.close()
+	if len(ledger.entries) != 3 {
+		t.Fatalf("expected 3 ledger entries, got %d", len(ledger.entries))
+	}
+}
+
+func TestWatchRetriesFailedDemos(t *testing.T) {
+	folderPath := t.TempDir()
+	for name, content := range map[string][]byte{
+		"match.dem":  buildSource1Demo("de_dust2"),
+		"broken.dem": buildSource1Demo("de_inferno"),
+	} {
+		if err := os.WriteFile(filepath.Join(folderPath, name), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var analyzedDemoPaths []string
+	watcher := newTestWatcher(t, folderPath, &analyzedDemoPaths)
+	watcher.analyze = func(ctx context.Context, demoPath string) error {
+		analyzedDemoPaths = append(analyzedDemoPaths, filepath.Base(demoPath))
+		if filepath.Base(demoPath) == "broken.dem" {
+			return errors.New("unexpected EOF")
+		}
+		return nil
+	}
+	ctx := context.Background()
+	for range 3 {
+		if err := watcher.scan(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	slices.Sort(analyzedDemoPaths)
+	if !slices.Equal(analyzedDemoPaths, []string{"broken.dem", "match.dem"}) {
+		t.Fatalf("expected each demo to be analyzed once, got %v", analyzedDemoPaths)
+	}
+
+	analyzedDemoPaths = nil
+	watcher = newTestWatcher(t, folderPath, &analyzedDemoPaths)
+	for range 2 {
+		if err := watcher.scan(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !slices.Equal(analyzedDemoPaths, []string{"broken.dem"}) {
+		t.Fatalf("expected only the failed demo to be analyzed again after a restart, got %v", analyzedDemoPaths)
+	}
+}
@@ -0,0 +1,33 @@
+package grpcserver
+
+import (
+	"fmt"
+)
+
+// codec sends and receives the messages as bytes. They are encoded and decoded with protowire like the protobuf
+// export, which avoids depending on code generated from the .proto files.
+type codec struct{}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	message, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+
+	return message, nil
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	message, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+	// gRPC doesn't reuse data once it has been decoded.
+	*message = data
+
+	return nil
+}
+
+func (codec) Name() string {
+	return "proto"
+}
@@ -0,0 +1,164 @@
+package grpcserver
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/akiver/cs-demo-analyzer/internal/demo"
+	"github.com/akiver/cs-demo-analyzer/pkg/api"
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+)
+
+// analyzeRequest is the AnalyzeDemoRequest message, see proto/csda/v1/analyzer.proto.
+type analyzeRequest struct {
+	demoPath     string
+	demo         []byte
+	demoFileName string
+	info         []byte
+	options      api.AnalyzeDemoOptions
+}
+
+// consumeFields calls fn with each field of the message, the value is the raw value of the field.
+func consumeFields(message []byte, fn func(number protowire.Number, fieldType protowire.Type, value []byte) error) error {
+	for len(message) > 0 {
+		number, fieldType, length := protowire.ConsumeTag(message)
+		if length < 0 {
+			return protowire.ParseError(length)
+		}
+		message = message[length:]
+
+		length = protowire.ConsumeFieldValue(number, fieldType, message)
+		if length < 0 {
+			return protowire.ParseError(length)
+		}
+		err := fn(number, fieldType, message[:length])
+		if err != nil {
+			return err
+		}
+		message = message[length:]
+	}
+
+	return nil
+}
+
+func parseBytes(fieldType protowire.Type, value []byte) ([]byte, error) {
+	if fieldType != protowire.BytesType {
+		return nil, fmt.Errorf("unexpected wire type %d", fieldType)
+	}
+	bytes, length := protowire.ConsumeBytes(value)
+	if length < 0 {
+		return nil, protowire.ParseError(length)
+	}
+
+	return bytes, nil
+}
+
+func parseBool(fieldType protowire.Type, value []byte) (bool, error) {
+	if fieldType != protowire.VarintType {
+		return false, fmt.Errorf("unexpected wire type %d", fieldType)
+	}
+	varint, length := protowire.ConsumeVarint(value)
+	if length < 0 {
+		return false, protowire.ParseError(length)
+	}
+
+	return varint != 0, nil
+}
+
+func parseOptions(message []byte, options *api.AnalyzeDemoOptions) error {
+	return consumeFields(message, func(number protowire.Number, fieldType protowire.Type, value []byte) error {
+		var bytes []byte
+		var err error
+		switch number {
+		case 1:
+			bytes, err = parseBytes(fieldType, value)
+			options.Source = constants.DemoSource(bytes)
+		case 2:
+			options.IncludePositions, err = parseBool(fieldType, value)
+		case 3:
+			bytes, err = parseBytes(fieldType, value)
+			options.IncludeEvents = append(options.IncludeEvents, constants.EventCategory(bytes))
+		case 4:
+			bytes, err = parseBytes(fieldType, value)
+			options.ExcludeEvents = append(options.ExcludeEvents, constants.EventCategory(bytes))
+		case 5:
+			options.ComputeContentHash, err = parseBool(fieldType, value)
+		}
+
+		return err
+	})
+}
+
+// parseAnalyzeRequest decodes the request, unknown fields are ignored.
+func parseAnalyzeRequest(message []byte) (*analyzeRequest, error) {
+	request := &analyzeRequest{}
+	err := consumeFields(message, func(number protowire.Number, fieldType protowire.Type, value []byte) error {
+		var bytes []byte
+		var err error
+		switch number {
+		case 1:
+			bytes, err = parseBytes(fieldType, value)
+			request.demoPath = string(bytes)
+		case 2:
+			request.demo, err = parseBytes(fieldType, value)
+		case 3:
+			bytes, err = parseBytes(fieldType, value)
+			request.demoFileName = string(bytes)
+		case 4:
+			request.info, err = parseBytes(fieldType, value)
+		case 5:
+			bytes, err = parseBytes(fieldType, value)
+			if err == nil {
+				err = parseOptions(bytes, &request.options)
+			}
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid request: %v", err)
+	}
+
+	return request, nil
+}
+
+func (request *analyzeRequest) validate() error {
+	if request.demoPath == "" && len(request.demo) == 0 {
+		return status.Error(codes.InvalidArgument, "a demo must be uploaded or a demo path must be provided")
+	}
+
+	if request.options.Source != "" {
+		if err := api.ValidateDemoSource(request.options.Source); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	for _, category := range append(request.options.IncludeEvents, request.options.ExcludeEvents...) {
+		if err := api.ValidateEventCategory(category); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// resolveDemoPath returns the absolute path of a demo located in the demos folder.
+func (service *service) resolveDemoPath(demoPath string) (string, error) {
+	absolutePath, err := demo.ResolvePath(service.options.DemosFolderPath, demoPath)
+	switch {
+	case err == nil:
+		return absolutePath, nil
+	case errors.Is(err, demo.ErrPathDisabled):
+		return "", status.Error(codes.PermissionDenied, "analyzing demos from a path is disabled, the server must be started with a demos folder")
+	case errors.Is(err, demo.ErrPathOutsideFolder):
+		return "", status.Errorf(codes.PermissionDenied, "the path %q is outside of the demos folder", demoPath)
+	case errors.Is(err, demo.ErrNotFound):
+		return "", status.Errorf(codes.NotFound, "demo file %q not found", demoPath)
+	}
+
+	return "", status.Error(codes.Internal, err.Error())
+}
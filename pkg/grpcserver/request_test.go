@@ -0,0 +1,107 @@
+package grpcserver
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+)
+
+// readProtoFields returns the field numbers of a message of proto/csda/v1/analyzer.proto by field name.
+func readProtoFields(t *testing.T, messageName string) map[string]protowire.Number {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("..", "..", "proto", "csda", "v1", "analyzer.proto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := regexp.MustCompile(`(?s)message ` + messageName + ` \{(.*?)\n\}`).FindSubmatch(content)
+	if message == nil {
+		t.Fatalf("message %s not found", messageName)
+	}
+
+	fields := make(map[string]protowire.Number)
+	for _, field := range regexp.MustCompile(`(?m)^\s*(?:repeated )?\w+ (\w+) = (\d+);`).FindAllSubmatch(message[1], -1) {
+		number, err := strconv.Atoi(string(field[2]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		fields[string(field[1])] = protowire.Number(number)
+	}
+
+	return fields
+}
+
+// TestParseAnalyzeRequestFieldNumbers fails when the fields decoded by parseAnalyzeRequest don't match the field numbers
+// of the .proto file.
+func TestParseAnalyzeRequestFieldNumbers(t *testing.T) {
+	optionsFields := readProtoFields(t, "AnalyzeDemoOptions")
+	requestFields := readProtoFields(t, "AnalyzeDemoRequest")
+	if len(optionsFields) != 5 || len(requestFields) != 5 {
+		t.Fatalf("expected 5 fields in each message, got %d options fields and %d request fields", len(optionsFields), len(requestFields))
+	}
+
+	appendString := func(message []byte, number protowire.Number, value string) []byte {
+		return protowire.AppendString(protowire.AppendTag(message, number, protowire.BytesType), value)
+	}
+	appendBool := func(message []byte, number protowire.Number) []byte {
+		return protowire.AppendVarint(protowire.AppendTag(message, number, protowire.VarintType), 1)
+	}
+
+	var options []byte
+	options = appendString(options, optionsFields["source"], string(constants.DemoSourceEbot))
+	options = appendBool(options, optionsFields["include_positions"])
+	options = appendString(options, optionsFields["include_events"], string(constants.EventCategoryKills))
+	options = appendString(options, optionsFields["include_events"], string(constants.EventCategoryDamages))
+	options = appendString(options, optionsFields["exclude_events"], string(constants.EventCategoryShots))
+	options = appendBool(options, optionsFields["compute_content_hash"])
+
+	var message []byte
+	message = appendString(message, requestFields["demo_path"], "folder/myDemo.dem")
+	message = appendString(message, requestFields["demo"], "demo")
+	message = appendString(message, requestFields["demo_file_name"], "myDemo.dem")
+	message = appendString(message, requestFields["info"], "info")
+	message = appendString(message, requestFields["options"], string(options))
+
+	request, err := parseAnalyzeRequest(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if request.demoPath != "folder/myDemo.dem" {
+		t.Errorf("expected demo path %q, got %q", "folder/myDemo.dem", request.demoPath)
+	}
+	if string(request.demo) != "demo" {
+		t.Errorf("expected demo %q, got %q", "demo", request.demo)
+	}
+	if request.demoFileName != "myDemo.dem" {
+		t.Errorf("expected demo file name %q, got %q", "myDemo.dem", request.demoFileName)
+	}
+	if string(request.info) != "info" {
+		t.Errorf("expected info %q, got %q", "info", request.info)
+	}
+	if request.options.Source != constants.DemoSourceEbot {
+		t.Errorf("expected source %q, got %q", constants.DemoSourceEbot, request.options.Source)
+	}
+	if !request.options.IncludePositions {
+		t.Error("expected positions to be included")
+	}
+	expectedIncludeEvents := []constants.EventCategory{constants.EventCategoryKills, constants.EventCategoryDamages}
+	if !slices.Equal(request.options.IncludeEvents, expectedIncludeEvents) {
+		t.Errorf("expected included events %v, got %v", expectedIncludeEvents, request.options.IncludeEvents)
+	}
+	expectedExcludeEvents := []constants.EventCategory{constants.EventCategoryShots}
+	if !slices.Equal(request.options.ExcludeEvents, expectedExcludeEvents) {
+		t.Errorf("expected excluded events %v, got %v", expectedExcludeEvents, request.options.ExcludeEvents)
+	}
+	if !request.options.ComputeContentHash {
+		t.Error("expected the content hash to be computed")
+	}
+}
@@ -0,0 +1,268 @@
+// Package grpcserver exposes the analysis of demos over gRPC, the service is defined in proto/csda/v1/analyzer.proto.
+//
+// AnalyzeDemo returns the records of the match as written by the protobuf export. StreamEvents sends the kills,
+// damages, player positions and rounds of the demo while it's analyzed, followed by the match.
+// Demos are uploaded in the request or located in the demos folder of the server.
+package grpcserver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/akiver/cs-demo-analyzer/pkg/api"
+)
+
+// Default maximum size of uploaded demos, requests are entirely kept in memory.
+const DefaultMaxUploadSize int64 = 512 << 20
+
+// Maximum size of the request fields other than the demo.
+const maxRequestOverhead = 2 << 20
+
+type Options struct {
+	// Number of demos analyzed at the same time, the number of CPUs if not set. Requests wait until an analysis
+	// finishes when the limit is reached.
+	Concurrency int
+	// Folder containing the demos that can be analyzed from their path, analyzing demos from a path is disabled if
+	// it's not set.
+	DemosFolderPath string
+	// Maximum size of uploaded demos in bytes.
+	MaxUploadSize int64
+}
+
+type service struct {
+	options Options
+	// Limits the number of analyses running at the same time.
+	slots chan struct{}
+	// Analyzes the demo of a request, replaced in tests.
+	analyze func(ctx context.Context, request *analyzeRequest) (*api.Match, error)
+}
+
+// New returns a gRPC server with the AnalyzerService registered.
+// Messages are encoded without generated code, other services registered on the server must not use generated
+// messages.
+func New(options Options) *grpc.Server {
+	if options.Concurrency < 1 {
+		options.Concurrency = runtime.NumCPU()
+	}
+	if options.MaxUploadSize <= 0 {
+		options.MaxUploadSize = DefaultMaxUploadSize
+	}
+
+	return newServer(&service{
+		options: options,
+		slots:   make(chan struct{}, options.Concurrency),
+		analyze: analyzeDemo,
+	})
+}
+
+func newServer(service *service) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ForceServerCodec(codec{}),
+		grpc.MaxRecvMsgSize(int(service.options.MaxUploadSize)+maxRequestOverhead),
+	)
+	server.RegisterService(&serviceDesc, service)
+
+	return server
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: "csda.v1.AnalyzerService",
+	// There is no generated interface to implement.
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AnalyzeDemo",
+			Handler:    handleAnalyzeDemo,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamEvents",
+			Handler:       handleStreamEvents,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "csda/v1/analyzer.proto",
+}
+
+func handleAnalyzeDemo(srv any, ctx context.Context, decode func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	var message []byte
+	if err := decode(&message); err != nil {
+		return nil, err
+	}
+
+	service := srv.(*service)
+	if interceptor == nil {
+		return service.analyzeDemo(ctx, message)
+	}
+
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/csda.v1.AnalyzerService/AnalyzeDemo",
+	}
+
+	return interceptor(ctx, message, info, func(ctx context.Context, request any) (any, error) {
+		return service.analyzeDemo(ctx, request.([]byte))
+	})
+}
+
+func handleStreamEvents(srv any, stream grpc.ServerStream) error {
+	var message []byte
+	if err := stream.RecvMsg(&message); err != nil {
+		return err
+	}
+
+	return srv.(*service).streamEvents(message, stream)
+}
+
+func analyzeDemo(ctx context.Context, request *analyzeRequest) (*api.Match, error) {
+	if request.demoPath != "" {
+		return api.AnalyzeDemoContext(ctx, request.demoPath, request.options)
+	}
+
+	return api.AnalyzeDemoFromReaderContext(ctx, bytes.NewReader(request.demo), request.demoFileName, request.options)
+}
+
+func (service *service) parseRequest(message []byte) (*analyzeRequest, error) {
+	request, err := parseAnalyzeRequest(message)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := request.validate(); err != nil {
+		return nil, err
+	}
+
+	if request.demoPath != "" {
+		request.demoPath, err = service.resolveDemoPath(request.demoPath)
+		if err != nil {
+			return nil, err
+		}
+		return request, nil
+	}
+
+	request.demoFileName = filepath.Base(request.demoFileName)
+	if request.demoFileName == "." || request.demoFileName == ".." || request.demoFileName == string(filepath.Separator) {
+		request.demoFileName = "demo.dem"
+	}
+	request.options.MatchInfo = request.info
+
+	return request, nil
+}
+
+// run analyzes the demo once an analysis slot is available.
+func (service *service) run(ctx context.Context, request *analyzeRequest) (match *api.Match, err error) {
+	select {
+	case service.slots <- struct{}{}:
+		defer func() { <-service.slots }()
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+
+	// The parser may panic with corrupted demos, recover to not stop the server.
+	defer func() {
+		if r := recover(); r != nil {
+			match = nil
+			err = newStatusError(fmt.Errorf("%v", r))
+		}
+	}()
+
+	match, err = service.analyze(ctx, request)
+	if err != nil {
+		return nil, newStatusError(err)
+	}
+
+	return match, nil
+}
+
+// newStatusError returns the status of an analysis error, the code of the error (see api.ErrorCode) is available in
+// the ErrorInfo details.
+func newStatusError(err error) error {
+	code := codes.InvalidArgument
+	var cancelledErr *api.CancelledError
+	switch {
+	case errors.As(err, &cancelledErr):
+		code = status.FromContextError(cancelledErr.Err).Code()
+	case api.ErrorCode(err) == "Error":
+		code = codes.Internal
+	}
+
+	errStatus := status.New(code, err.Error())
+	detailedStatus, detailsErr := errStatus.WithDetails(&errdetails.ErrorInfo{
+		Reason: api.ErrorCode(err),
+		Domain: "csda",
+	})
+	if detailsErr != nil {
+		return errStatus.Err()
+	}
+
+	return detailedStatus.Err()
+}
+
+func (service *service) analyzeDemo(ctx context.Context, message []byte) ([]byte, error) {
+	request, err := service.parseRequest(message)
+	if err != nil {
+		return nil, err
+	}
+
+	match, err := service.run(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	var response []byte
+	err = api.WriteProtobufRecords(match, func(record []byte) error {
+		response = protowire.AppendTag(response, 1, protowire.BytesType)
+		response = protowire.AppendBytes(response, record)
+
+		return nil
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return response, nil
+}
+
+func (service *service) streamEvents(message []byte, stream grpc.ServerStream) error {
+	request, err := service.parseRequest(message)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	sink := &streamSink{
+		stream: stream,
+		cancel: cancel,
+	}
+	request.options.EventSink = sink
+	// Positions are sent when their round ends, they don't have to be kept until the end of the analysis.
+	request.options.DiscardEvents = true
+
+	match, err := service.run(ctx, request)
+	if sink.err != nil {
+		return sink.err
+	}
+	if err != nil {
+		return err
+	}
+
+	record, err := api.MarshalProtobufRecord(match.Checksum, match)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return sink.sendRecord(record)
+}
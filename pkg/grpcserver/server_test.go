@@ -0,0 +1,233 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/akiver/cs-demo-analyzer/pkg/api"
+	"github.com/markus-wa/demoinfocs-golang/v4/pkg/demoinfocs/common"
+)
+
+func newTestClient(t *testing.T, options Options, analyze func(ctx context.Context, request *analyzeRequest) (*api.Match, error)) *grpc.ClientConn {
+	t.Helper()
+
+	options.Concurrency = 1
+	options.MaxUploadSize = DefaultMaxUploadSize
+	server := newServer(&service{
+		options: options,
+		slots:   make(chan struct{}, options.Concurrency),
+		analyze: analyze,
+	})
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(codec{})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+	})
+
+	return conn
+}
+
+func newTestMatch() *api.Match {
+	sideA, sideB := common.TeamCounterTerrorists, common.TeamTerrorists
+
+	return &api.Match{
+		Checksum: "1",
+		TeamA:    &api.Team{CurrentSide: &sideA},
+		TeamB:    &api.Team{CurrentSide: &sideB},
+	}
+}
+
+func appendString(message []byte, number protowire.Number, value string) []byte {
+	message = protowire.AppendTag(message, number, protowire.BytesType)
+
+	return protowire.AppendString(message, value)
+}
+
+// parseOneofNumbers returns the field number of each oneof field contained in the messages, i.e. the number of the
+// entity of a Record. Messages are the raw value of the field number of their parent message.
+func parseOneofNumbers(t *testing.T, messages [][]byte) []protowire.Number {
+	t.Helper()
+
+	var numbers []protowire.Number
+	for _, message := range messages {
+		err := consumeFields(message, func(number protowire.Number, _ protowire.Type, _ []byte) error {
+			numbers = append(numbers, number)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return numbers
+}
+
+func TestAnalyzeDemo(t *testing.T) {
+	conn := newTestClient(t, Options{}, func(ctx context.Context, request *analyzeRequest) (*api.Match, error) {
+		if string(request.demo) != "demo content" || string(request.options.MatchInfo) != "info content" ||
+			request.demoFileName != "myDemo.dem" || !request.options.IncludePositions {
+			t.Errorf("unexpected request %+v", request)
+		}
+
+		return newTestMatch(), nil
+	})
+
+	var options []byte
+	options = protowire.AppendTag(options, 2, protowire.VarintType)
+	options = protowire.AppendVarint(options, 1)
+	var request []byte
+	request = appendString(request, 2, "demo content")
+	request = appendString(request, 3, "path/to/myDemo.dem")
+	request = appendString(request, 4, "info content")
+	request = protowire.AppendTag(request, 5, protowire.BytesType)
+	request = protowire.AppendBytes(request, options)
+
+	var response []byte
+	err := conn.Invoke(context.Background(), "/csda.v1.AnalyzerService/AnalyzeDemo", request, &response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var records [][]byte
+	err = consumeFields(response, func(number protowire.Number, fieldType protowire.Type, value []byte) error {
+		record, err := parseBytes(fieldType, value)
+		records = append(records, record)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The match and its 2 teams.
+	numbers := parseOneofNumbers(t, records)
+	if !slices.Equal(numbers, []protowire.Number{1, 2, 2}) {
+		t.Fatalf("unexpected records %v", numbers)
+	}
+}
+
+func TestStreamEvents(t *testing.T) {
+	conn := newTestClient(t, Options{}, func(ctx context.Context, request *analyzeRequest) (*api.Match, error) {
+		sink := request.options.EventSink
+		sink.OnKill(&api.Kill{RoundNumber: 1})
+		sink.OnMatchReset()
+		sink.OnKill(&api.Kill{RoundNumber: 1})
+		sink.OnDamage(&api.Damage{RoundNumber: 1})
+		sink.OnPlayerPosition(&api.PlayerPosition{RoundNumber: 1})
+
+		return newTestMatch(), nil
+	})
+
+	stream, err := conn.NewStream(
+		context.Background(),
+		&grpc.StreamDesc{ServerStreams: true},
+		"/csda.v1.AnalyzerService/StreamEvents",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.SendMsg(appendString(nil, 2, "demo content")); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+
+	var events [][]byte
+	for {
+		var response []byte
+		err := stream.RecvMsg(&response)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, response)
+	}
+
+	// The objects of the round are not sent because the round didn't end, only the match reset and the match are sent.
+	numbers := parseOneofNumbers(t, events)
+	if !slices.Equal(numbers, []protowire.Number{2, 1}) {
+		t.Fatalf("unexpected events %v", numbers)
+	}
+
+	var records [][]byte
+	err = consumeFields(events[1], func(_ protowire.Number, fieldType protowire.Type, value []byte) error {
+		record, err := parseBytes(fieldType, value)
+		records = append(records, record)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numbers = parseOneofNumbers(t, records); !slices.Equal(numbers, []protowire.Number{1}) {
+		t.Fatalf("unexpected records %v", numbers)
+	}
+}
+
+func TestAnalyzeDemoErrors(t *testing.T) {
+	demosFolderPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(demosFolderPath, "myDemo.dem"), []byte("demo content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conn := newTestClient(t, Options{DemosFolderPath: demosFolderPath}, func(ctx context.Context, request *analyzeRequest) (*api.Match, error) {
+		return nil, api.ErrUnknownSource
+	})
+
+	tests := []struct {
+		path           string
+		expectedCode   codes.Code
+		expectedReason string
+	}{
+		{"myDemo.dem", codes.InvalidArgument, "UnknownSource"},
+		{"../myDemo.dem", codes.PermissionDenied, ""},
+		{"missing.dem", codes.NotFound, ""},
+	}
+
+	for _, test := range tests {
+		var response []byte
+		err := conn.Invoke(context.Background(), "/csda.v1.AnalyzerService/AnalyzeDemo", appendString(nil, 1, test.path), &response)
+		errStatus := status.Convert(err)
+		if errStatus.Code() != test.expectedCode {
+			t.Errorf("%s: expected code %s, got %s", test.path, test.expectedCode, errStatus.Code())
+		}
+
+		var reason string
+		for _, detail := range errStatus.Details() {
+			if info, ok := detail.(*errdetails.ErrorInfo); ok {
+				reason = info.Reason
+			}
+		}
+		if reason != test.expectedReason {
+			t.Errorf("%s: expected reason %q, got %q", test.path, test.expectedReason, reason)
+		}
+	}
+}
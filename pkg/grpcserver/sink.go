@@ -0,0 +1,102 @@
+package grpcserver
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/akiver/cs-demo-analyzer/pkg/api"
+)
+
+type pendingEntity struct {
+	roundNumber int
+	entity      any
+}
+
+// streamSink is an EventSink that sends the kills, damages and player positions of a round when the round ends because
+// some of their values are updated until then, followed by the round.
+// Objects of incomplete rounds are dropped. When sending a message fails, the analysis is cancelled.
+type streamSink struct {
+	api.NopEventSink
+	stream  grpc.ServerStream
+	cancel  func()
+	pending []pendingEntity
+	err     error
+}
+
+func (sink *streamSink) OnKill(kill *api.Kill) {
+	sink.pending = append(sink.pending, pendingEntity{kill.RoundNumber, kill})
+}
+
+func (sink *streamSink) OnDamage(damage *api.Damage) {
+	sink.pending = append(sink.pending, pendingEntity{damage.RoundNumber, damage})
+}
+
+func (sink *streamSink) OnPlayerPosition(position *api.PlayerPosition) {
+	sink.pending = append(sink.pending, pendingEntity{position.RoundNumber, position})
+}
+
+func (sink *streamSink) OnRoundEnd(round *api.Round) {
+	var nextRounds []pendingEntity
+	for _, pending := range sink.pending {
+		if pending.roundNumber > round.Number {
+			nextRounds = append(nextRounds, pending)
+		} else if pending.roundNumber == round.Number {
+			sink.sendEntity(pending.entity)
+		}
+	}
+	sink.pending = nextRounds
+	sink.sendEntity(round)
+}
+
+// OnRoundReset drops the objects of the round, the round didn't end so they have not been sent.
+func (sink *streamSink) OnRoundReset(roundNumber int) {
+	var otherRounds []pendingEntity
+	for _, pending := range sink.pending {
+		if pending.roundNumber != roundNumber {
+			otherRounds = append(otherRounds, pending)
+		}
+	}
+	sink.pending = otherRounds
+}
+
+func (sink *streamSink) OnMatchReset() {
+	sink.pending = nil
+	// Empty MatchReset message.
+	sink.send(protowire.AppendBytes(protowire.AppendTag(nil, 2, protowire.BytesType), nil))
+}
+
+// sendEntity sends the Record of the entity, the match checksum is not known yet.
+func (sink *streamSink) sendEntity(entity any) {
+	record, err := api.MarshalProtobufRecord("", entity)
+	if err != nil {
+		sink.fail(err)
+		return
+	}
+
+	sink.fail(sink.sendRecord(record))
+}
+
+func (sink *streamSink) sendRecord(record []byte) error {
+	response := protowire.AppendTag(nil, 1, protowire.BytesType)
+	response = protowire.AppendBytes(response, record)
+
+	return sink.send(response)
+}
+
+func (sink *streamSink) send(response []byte) error {
+	if sink.err != nil {
+		return sink.err
+	}
+
+	err := sink.stream.SendMsg(response)
+	sink.fail(err)
+
+	return err
+}
+
+func (sink *streamSink) fail(err error) {
+	if err != nil && sink.err == nil {
+		sink.err = err
+		sink.cancel()
+	}
+}
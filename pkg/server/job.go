@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/akiver/cs-demo-analyzer/pkg/api"
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+)
+
+type jobStatus string
+
+const (
+	jobStatusQueued    jobStatus = "queued"
+	jobStatusRunning   jobStatus = "running"
+	jobStatusSucceeded jobStatus = "succeeded"
+	jobStatusFailed    jobStatus = "failed"
+)
+
+// job is the analysis of a demo, it's queued until a worker is available.
+type job struct {
+	id           string
+	demoPath     string
+	demoFileName string
+	// Folder of the job that contains the uploaded demo and the export, it's removed when the job expires.
+	folderPath string
+	outputPath string
+	options    api.AnalyzeAndExportDemoOptions
+	// The job is returned as soon as it's queued instead of its export.
+	isAsync bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	// Closed when the job is finished.
+	done       chan struct{}
+	mutex      sync.Mutex
+	status     jobStatus
+	progress   float32
+	err        error
+	createdAt  time.Time
+	finishedAt time.Time
+}
+
+type errorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type jobResponse struct {
+	ID           string                 `json:"id"`
+	Status       jobStatus              `json:"status"`
+	Progress     float32                `json:"progress"` // Between 0 and 1
+	DemoFileName string                 `json:"demoFileName"`
+	Format       constants.ExportFormat `json:"format"`
+	Error        *errorResponse         `json:"error,omitempty"`
+	CreatedAt    time.Time              `json:"createdAt"`
+	FinishedAt   *time.Time             `json:"finishedAt,omitempty"`
+}
+
+func newJobID() string {
+	bytes := make([]byte, 16)
+	_, _ = rand.Read(bytes)
+
+	return hex.EncodeToString(bytes)
+}
+
+func (job *job) setRunning() {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+
+	job.status = jobStatusRunning
+}
+
+func (job *job) setProgress(progress api.AnalyzeProgress) {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+
+	job.progress = progress.Progress
+}
+
+func (job *job) finish(err error) {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+
+	if job.status == jobStatusSucceeded || job.status == jobStatusFailed {
+		return
+	}
+
+	job.err = err
+	job.finishedAt = time.Now()
+	if err != nil {
+		job.status = jobStatusFailed
+	} else {
+		job.status = jobStatusSucceeded
+		job.progress = 1
+	}
+	close(job.done)
+	job.cancel()
+}
+
+func (job *job) isFinished() bool {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+
+	return job.status == jobStatusSucceeded || job.status == jobStatusFailed
+}
+
+// isExpired returns true if the job has been finished for more than ttl.
+func (job *job) isExpired(ttl time.Duration) bool {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+
+	return !job.finishedAt.IsZero() && time.Since(job.finishedAt) > ttl
+}
+
+func (job *job) response() jobResponse {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+
+	response := jobResponse{
+		ID:           job.id,
+		Status:       job.status,
+		Progress:     job.progress,
+		DemoFileName: job.demoFileName,
+		Format:       job.options.Format,
+		CreatedAt:    job.createdAt,
+	}
+	if job.err != nil {
+		response.Error = &errorResponse{
+			Code:    api.ErrorCode(job.err),
+			Message: job.err.Error(),
+		}
+	}
+	if !job.finishedAt.IsZero() {
+		finishedAt := job.finishedAt
+		response.FinishedAt = &finishedAt
+	}
+
+	return response
+}
+
+func analyzeJob(ctx context.Context, job *job) error {
+	options := job.options
+	options.Progress = job.setProgress
+
+	return api.AnalyzeAndExportDemoContext(ctx, job.demoPath, job.outputPath, options)
+}
@@ -0,0 +1,286 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/akiver/cs-demo-analyzer/internal/demo"
+	"github.com/akiver/cs-demo-analyzer/pkg/api"
+	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
+)
+
+// Maximum size of the form fields and of the JSON body of analyze requests.
+const maxFieldSize = 64 << 10
+
+// Maximum size of uploaded .info files, they usually weigh a few KB.
+const maxInfoFileSize = 1 << 20
+
+// analyzeRequest contains the options of an analyze request, they may be set with query parameters, multipart form
+// fields or a JSON body.
+type analyzeRequest struct {
+	// Path of a demo on the server, relative to the demos folder.
+	Path        string                    `json:"path"`
+	Format      constants.ExportFormat    `json:"format"`
+	Source      constants.DemoSource      `json:"source"`
+	Positions   bool                      `json:"positions"`
+	Include     []constants.EventCategory `json:"include"`
+	Exclude     []constants.EventCategory `json:"exclude"`
+	Minify      bool                      `json:"minify"`
+	Compression constants.Compression     `json:"compress"`
+	ContentHash bool                      `json:"contentHash"`
+	Async       bool                      `json:"async"`
+}
+
+// requestError is an error caused by the request, it's returned to the client with its HTTP status.
+type requestError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (err *requestError) Error() string {
+	return err.message
+}
+
+func newBadRequestError(message string) *requestError {
+	return &requestError{
+		status:  http.StatusBadRequest,
+		code:    "InvalidRequest",
+		message: message,
+	}
+}
+
+func parseEventCategories(value string) []constants.EventCategory {
+	var categories []constants.EventCategory
+	for _, category := range strings.Split(value, ",") {
+		category = strings.TrimSpace(category)
+		if category != "" {
+			categories = append(categories, constants.EventCategory(category))
+		}
+	}
+
+	return categories
+}
+
+func (request *analyzeRequest) setOption(name string, value string) error {
+	var err error
+	switch name {
+	case "path":
+		request.Path = value
+	case "format":
+		request.Format = constants.ExportFormat(value)
+	case "source":
+		request.Source = constants.DemoSource(value)
+	case "positions":
+		request.Positions, err = strconv.ParseBool(value)
+	case "include":
+		request.Include = parseEventCategories(value)
+	case "exclude":
+		request.Exclude = parseEventCategories(value)
+	case "minify":
+		request.Minify, err = strconv.ParseBool(value)
+	case "compress":
+		request.Compression = constants.Compression(value)
+	case "contentHash":
+		request.ContentHash, err = strconv.ParseBool(value)
+	case "async":
+		request.Async, err = strconv.ParseBool(value)
+	}
+
+	if err != nil {
+		return newBadRequestError(fmt.Sprintf("invalid %s value %q", name, value))
+	}
+
+	return nil
+}
+
+func (request *analyzeRequest) validate() error {
+	if request.Format == "" {
+		request.Format = constants.ExportFormatJSON
+	}
+
+	if err := api.ValidateExportFormat(request.Format); err != nil {
+		return newBadRequestError(err.Error())
+	}
+
+	// The export is written into the job folder, there is no database connection to provide.
+	if request.Format == constants.ExportFormatPostgres {
+		return newBadRequestError("the postgres format is not supported by the server")
+	}
+
+	if err := api.ValidateCompression(request.Compression, request.Format); err != nil {
+		return newBadRequestError(err.Error())
+	}
+
+	if request.Source != "" {
+		if err := api.ValidateDemoSource(request.Source); err != nil {
+			return newBadRequestError(err.Error())
+		}
+	}
+
+	for _, category := range append(request.Include, request.Exclude...) {
+		if err := api.ValidateEventCategory(category); err != nil {
+			return newBadRequestError(err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (request *analyzeRequest) exportOptions() api.AnalyzeAndExportDemoOptions {
+	return api.AnalyzeAndExportDemoOptions{
+		IncludePositions:   request.Positions,
+		Source:             request.Source,
+		IncludeEvents:      request.Include,
+		ExcludeEvents:      request.Exclude,
+		Format:             request.Format,
+		MinifyJSON:         request.Minify,
+		Compression:        request.Compression,
+		ComputeContentHash: request.ContentHash,
+	}
+}
+
+// readUpload saves the files of a multipart request into the folder and returns the path of the demo.
+// The "demo" part is the demo and the optional "info" part is its .info file, other parts are options.
+func readUpload(reader io.Reader, boundary string, folderPath string, request *analyzeRequest) (string, error) {
+	multipartReader := multipart.NewReader(reader, boundary)
+	var demoPath string
+	var matchInfo []byte
+	for {
+		part, err := multipartReader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch part.FormName() {
+		case "demo":
+			fileName := filepath.Base(part.FileName())
+			if fileName == "." || fileName == ".." || fileName == string(filepath.Separator) {
+				fileName = "demo.dem"
+			}
+			demoPath = filepath.Join(folderPath, fileName)
+			err = saveFile(part, demoPath)
+		case "info":
+			matchInfo, err = io.ReadAll(io.LimitReader(part, maxInfoFileSize))
+		default:
+			var value []byte
+			value, err = io.ReadAll(io.LimitReader(part, maxFieldSize))
+			if err == nil {
+				err = request.setOption(part.FormName(), string(value))
+			}
+		}
+		part.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if demoPath == "" {
+		return "", newBadRequestError("the demo file is missing, it must be sent in the \"demo\" part")
+	}
+
+	// The .info file must be next to the demo to be used.
+	if len(matchInfo) > 0 {
+		err := os.WriteFile(demoPath+".info", matchInfo, 0644)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return demoPath, nil
+}
+
+func saveFile(reader io.Reader, filePath string) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, reader)
+
+	return errors.Join(err, file.Close())
+}
+
+// parseAnalyzeRequest reads the options of the request, the demo is saved into the folder if it's uploaded.
+// It returns the path of the demo to analyze.
+func (server *Server) parseAnalyzeRequest(r *http.Request, folderPath string) (*analyzeRequest, string, error) {
+	request := &analyzeRequest{}
+	for name, values := range r.URL.Query() {
+		if err := request.setOption(name, values[len(values)-1]); err != nil {
+			return nil, "", err
+		}
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	var demoPath string
+	switch mediaType {
+	case "multipart/form-data":
+		var err error
+		demoPath, err = readUpload(r.Body, params["boundary"], folderPath, request)
+		if err != nil {
+			return nil, "", err
+		}
+	case "application/json":
+		err := json.NewDecoder(io.LimitReader(r.Body, maxFieldSize)).Decode(request)
+		if err != nil {
+			return nil, "", newBadRequestError(fmt.Sprintf("invalid JSON body: %v", err))
+		}
+	}
+
+	if demoPath == "" {
+		if request.Path == "" {
+			return nil, "", newBadRequestError("a demo must be uploaded as multipart/form-data or a path must be provided")
+		}
+
+		var err error
+		demoPath, err = server.resolveDemoPath(request.Path)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := request.validate(); err != nil {
+		return nil, "", err
+	}
+
+	return request, demoPath, nil
+}
+
+// resolveDemoPath returns the absolute path of a demo located in the demos folder.
+func (server *Server) resolveDemoPath(demoPath string) (string, error) {
+	absolutePath, err := demo.ResolvePath(server.options.DemosFolderPath, demoPath)
+	switch {
+	case errors.Is(err, demo.ErrPathDisabled):
+		return "", &requestError{
+			status:  http.StatusForbidden,
+			code:    "PathNotAllowed",
+			message: "analyzing demos from a path is disabled, the server must be started with a demos folder",
+		}
+	case errors.Is(err, demo.ErrPathOutsideFolder):
+		return "", &requestError{
+			status:  http.StatusForbidden,
+			code:    "PathNotAllowed",
+			message: fmt.Sprintf("the path %q is outside of the demos folder", demoPath),
+		}
+	case errors.Is(err, demo.ErrNotFound):
+		return "", &requestError{
+			status:  http.StatusNotFound,
+			code:    "DemoNotFound",
+			message: fmt.Sprintf("demo file %q not found", demoPath),
+		}
+	}
+
+	return absolutePath, err
+}
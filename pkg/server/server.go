@@ -0,0 +1,454 @@
+// Package server exposes the analysis of demos over HTTP.
+//
+// POST /analyze analyzes a demo uploaded as multipart/form-data ("demo" and optional "info" parts) or located in the
+// demos folder of the server ({"path": "relative/path.dem"} JSON body). Options are set with query parameters, form
+// fields or JSON fields: format, source, positions, include, exclude, minify, compress, contentHash and async.
+// The response is the export, the JSON export by default. When async is true, the job is returned immediately with
+// the status 202 and the export is available with GET /jobs/{id}/result once the job succeeded.
+//
+// GET /jobs/{id} returns the status and the progress of a job, DELETE /jobs/{id} cancels it and deletes its files.
+package server
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/akiver/cs-demo-analyzer/pkg/api"
+)
+
+// Default number of jobs waiting for a worker.
+const DefaultQueueSize = 64
+
+// Default maximum size of uploaded demos.
+const DefaultMaxUploadSize int64 = 2 << 30
+
+// Default duration finished jobs are kept.
+const DefaultJobTTL = time.Hour
+
+type Options struct {
+	// Number of demos analyzed at the same time, the number of CPUs if not set.
+	Concurrency int
+	// Number of jobs waiting for a worker, requests are rejected with the status 503 when the queue is full.
+	QueueSize int
+	// Folder containing the demos that can be analyzed from their path, analyzing demos from a path is disabled if
+	// it's not set.
+	DemosFolderPath string
+	// Maximum size of uploaded demos in bytes.
+	MaxUploadSize int64
+	// Duration finished jobs and their export are kept, they are deleted afterwards.
+	JobTTL time.Duration
+	// Folder where uploaded demos and exports are written, the default temporary folder if not set.
+	TempFolderPath string
+}
+
+// Server is an http.Handler that analyzes demos with a pool of workers.
+type Server struct {
+	options Options
+	mux     *http.ServeMux
+	queue   chan *job
+	mutex   sync.Mutex
+	jobs    map[string]*job
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	// Analyzes the demo of a job, replaced in tests.
+	analyze func(ctx context.Context, job *job) error
+}
+
+// New starts the workers of the server, Close must be called to stop them.
+func New(options Options) *Server {
+	if options.Concurrency < 1 {
+		options.Concurrency = runtime.NumCPU()
+	}
+	if options.QueueSize < 1 {
+		options.QueueSize = DefaultQueueSize
+	}
+	if options.MaxUploadSize <= 0 {
+		options.MaxUploadSize = DefaultMaxUploadSize
+	}
+	if options.JobTTL <= 0 {
+		options.JobTTL = DefaultJobTTL
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	server := &Server{
+		options: options,
+		mux:     http.NewServeMux(),
+		queue:   make(chan *job, options.QueueSize),
+		jobs:    make(map[string]*job),
+		ctx:     ctx,
+		cancel:  cancel,
+		analyze: analyzeJob,
+	}
+
+	server.mux.HandleFunc("POST /analyze", server.handleAnalyze)
+	server.mux.HandleFunc("GET /jobs/{id}", server.handleGetJob)
+	server.mux.HandleFunc("DELETE /jobs/{id}", server.handleDeleteJob)
+	server.mux.HandleFunc("GET /jobs/{id}/result", server.handleGetJobResult)
+
+	for range options.Concurrency {
+		server.wg.Add(1)
+		go server.work()
+	}
+
+	server.wg.Add(1)
+	go server.deleteExpiredJobs()
+
+	return server
+}
+
+func (server *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.mux.ServeHTTP(w, r)
+}
+
+// Close cancels the jobs, waits for the workers to stop and deletes the files of all jobs.
+func (server *Server) Close() error {
+	server.cancel()
+	server.wg.Wait()
+
+	// Jobs deleted while they were queued are finished to delete their files.
+	for len(server.queue) > 0 {
+		job := <-server.queue
+		job.finish(&api.CancelledError{Err: context.Canceled})
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	var errs []error
+	for id, job := range server.jobs {
+		job.finish(&api.CancelledError{Err: context.Canceled})
+		errs = append(errs, os.RemoveAll(job.folderPath))
+		delete(server.jobs, id)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (server *Server) work() {
+	defer server.wg.Done()
+
+	for {
+		select {
+		case <-server.ctx.Done():
+			return
+		case job := <-server.queue:
+			// The job may have been deleted while it was queued.
+			if job.ctx.Err() != nil {
+				job.finish(&api.CancelledError{Err: job.ctx.Err()})
+				continue
+			}
+			job.setRunning()
+			job.finish(server.runJob(job))
+		}
+	}
+}
+
+func (server *Server) runJob(job *job) (err error) {
+	// The parser may panic with corrupted demos, recover to not stop the server.
+	defer func() {
+		if r := recover(); r != nil {
+			if recoveredErr, ok := r.(error); ok {
+				err = recoveredErr
+			} else {
+				err = fmt.Errorf("%v", r)
+			}
+		}
+	}()
+
+	return server.analyze(job.ctx, job)
+}
+
+func (server *Server) deleteExpiredJobs() {
+	defer server.wg.Done()
+
+	ticker := time.NewTicker(min(server.options.JobTTL, time.Minute))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-server.ctx.Done():
+			return
+		case <-ticker.C:
+			server.mutex.Lock()
+			for id, job := range server.jobs {
+				if job.isExpired(server.options.JobTTL) {
+					delete(server.jobs, id)
+					os.RemoveAll(job.folderPath)
+				}
+			}
+			server.mutex.Unlock()
+		}
+	}
+}
+
+func (server *Server) getJob(id string) *job {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return server.jobs[id]
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var requestErr *requestError
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &requestErr):
+		writeJSON(w, requestErr.status, errorResponse{Code: requestErr.code, Message: requestErr.message})
+	case errors.As(err, &maxBytesErr):
+		writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse{Code: "DemoTooLarge", Message: err.Error()})
+	default:
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Code: "Error", Message: err.Error()})
+	}
+}
+
+// writeJobError writes the error of a failed job, errors caused by the demo itself are client errors.
+func writeJobError(w http.ResponseWriter, err error) {
+	status := http.StatusUnprocessableEntity
+	code := api.ErrorCode(err)
+	switch code {
+	case "Error":
+		status = http.StatusInternalServerError
+	case "Cancelled":
+		status = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, status, errorResponse{Code: code, Message: err.Error()})
+}
+
+func (server *Server) handleAnalyze(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, server.options.MaxUploadSize)
+
+	folderPath, err := os.MkdirTemp(server.options.TempFolderPath, "csda-job-")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	job, err := server.newJob(r, folderPath)
+	if err != nil {
+		os.RemoveAll(folderPath)
+		writeError(w, err)
+		return
+	}
+
+	server.mutex.Lock()
+	select {
+	case server.queue <- job:
+		server.jobs[job.id] = job
+		server.mutex.Unlock()
+	default:
+		server.mutex.Unlock()
+		job.cancel()
+		os.RemoveAll(folderPath)
+		w.Header().Set("Retry-After", "10")
+		writeError(w, &requestError{
+			status:  http.StatusServiceUnavailable,
+			code:    "QueueFull",
+			message: "too many demos are waiting to be analyzed, retry later",
+		})
+		return
+	}
+
+	if job.isAsync {
+		w.Header().Set("Location", "/jobs/"+job.id)
+		writeJSON(w, http.StatusAccepted, job.response())
+		return
+	}
+
+	select {
+	case <-job.done:
+		server.writeJobResult(w, r, job)
+	case <-r.Context().Done():
+		// Nobody is waiting for the result anymore.
+		server.deleteJob(job)
+	}
+}
+
+func (server *Server) newJob(r *http.Request, folderPath string) (*job, error) {
+	demoFolderPath := filepath.Join(folderPath, "demo")
+	outputPath := filepath.Join(folderPath, "output")
+	for _, path := range []string{demoFolderPath, outputPath} {
+		if err := os.Mkdir(path, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	request, demoPath, err := server.parseAnalyzeRequest(r, demoFolderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(server.ctx)
+
+	return &job{
+		id:           newJobID(),
+		demoPath:     demoPath,
+		demoFileName: filepath.Base(demoPath),
+		folderPath:   folderPath,
+		outputPath:   outputPath,
+		options:      request.exportOptions(),
+		isAsync:      request.Async,
+		ctx:          ctx,
+		cancel:       cancel,
+		done:         make(chan struct{}),
+		status:       jobStatusQueued,
+		createdAt:    time.Now(),
+	}, nil
+}
+
+func (server *Server) deleteJob(job *job) {
+	server.mutex.Lock()
+	delete(server.jobs, job.id)
+	server.mutex.Unlock()
+
+	job.cancel()
+	// The worker stops using the files once the analysis is cancelled.
+	go func() {
+		<-job.done
+		os.RemoveAll(job.folderPath)
+	}()
+}
+
+func (server *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	job := server.getJob(r.PathValue("id"))
+	if job == nil {
+		writeError(w, &requestError{status: http.StatusNotFound, code: "JobNotFound", message: "job not found"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, job.response())
+}
+
+func (server *Server) handleDeleteJob(w http.ResponseWriter, r *http.Request) {
+	job := server.getJob(r.PathValue("id"))
+	if job == nil {
+		writeError(w, &requestError{status: http.StatusNotFound, code: "JobNotFound", message: "job not found"})
+		return
+	}
+
+	server.deleteJob(job)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (server *Server) handleGetJobResult(w http.ResponseWriter, r *http.Request) {
+	job := server.getJob(r.PathValue("id"))
+	if job == nil {
+		writeError(w, &requestError{status: http.StatusNotFound, code: "JobNotFound", message: "job not found"})
+		return
+	}
+
+	if !job.isFinished() {
+		writeError(w, &requestError{status: http.StatusConflict, code: "JobNotFinished", message: "the job is not finished"})
+		return
+	}
+
+	server.writeJobResult(w, r, job)
+}
+
+// writeJobResult writes the export of a finished job, exports that produce several files are written as a tar archive.
+func (server *Server) writeJobResult(w http.ResponseWriter, r *http.Request, job *job) {
+	if job.err != nil {
+		writeJobError(w, job.err)
+		return
+	}
+
+	entries, err := os.ReadDir(job.outputPath)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if len(entries) == 1 {
+		file, err := os.Open(filepath.Join(job.outputPath, entries[0].Name()))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		defer file.Close()
+
+		stat, err := file.Stat()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType(stat.Name()))
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": stat.Name()}))
+		http.ServeContent(w, r, stat.Name(), stat.ModTime(), file)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": job.demoFileName + ".tar",
+	}))
+	writer := tar.NewWriter(w)
+	for _, entry := range entries {
+		if err := writeTarFile(writer, filepath.Join(job.outputPath, entry.Name())); err != nil {
+			// The status has already been sent, the client detects the truncated archive.
+			return
+		}
+	}
+	writer.Close()
+}
+
+func writeTarFile(writer *tar.Writer, filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	header, err := tar.FileInfoHeader(stat, "")
+	if err != nil {
+		return err
+	}
+
+	if err = writer.WriteHeader(header); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(writer, file)
+
+	return err
+}
+
+func contentType(fileName string) string {
+	switch filepath.Ext(fileName) {
+	case ".json":
+		return "application/json"
+	case ".ndjson":
+		return "application/x-ndjson"
+	case ".gz":
+		return "application/gzip"
+	case ".zst":
+		return "application/zstd"
+	case ".csv":
+		return "text/csv"
+	}
+
+	return "application/octet-stream"
+}
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akiver/cs-demo-analyzer/pkg/api"
+)
+
+func newTestServer(t *testing.T, options Options, analyze func(ctx context.Context, job *job) error) *httptest.Server {
+	t.Helper()
+
+	options.TempFolderPath = t.TempDir()
+	server := New(options)
+	server.analyze = analyze
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(func() {
+		httpServer.Close()
+		if err := server.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	return httpServer
+}
+
+func newUploadRequest(t *testing.T, url string, fields map[string]string) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	part, err := writer.CreateFormFile("demo", "myDemo.dem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte("demo content"))
+	part, err = writer.CreateFormFile("info", "myDemo.dem.info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte("info content"))
+	writer.Close()
+
+	request, err := http.NewRequest(http.MethodPost, url+"/analyze", &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return request
+}
+
+func decodeResponse[T any](t *testing.T, response *http.Response, expectedStatus int) T {
+	t.Helper()
+
+	defer response.Body.Close()
+	var value T
+	if response.StatusCode != expectedStatus {
+		content, _ := io.ReadAll(response.Body)
+		t.Fatalf("expected status %d, got %d: %s", expectedStatus, response.StatusCode, content)
+	}
+	if err := json.NewDecoder(response.Body).Decode(&value); err != nil {
+		t.Fatal(err)
+	}
+
+	return value
+}
+
+func TestAnalyzeUpload(t *testing.T) {
+	httpServer := newTestServer(t, Options{}, func(ctx context.Context, job *job) error {
+		info, err := os.ReadFile(job.demoPath + ".info")
+		if err != nil {
+			return err
+		}
+		if job.options.Format != "json" || !job.options.MinifyJSON || string(info) != "info content" {
+			t.Errorf("unexpected job options %+v and .info file %q", job.options, info)
+		}
+
+		return os.WriteFile(filepath.Join(job.outputPath, "myDemo.json"), []byte(`{"checksum":"1"}`), 0644)
+	})
+
+	response, err := http.DefaultClient.Do(newUploadRequest(t, httpServer.URL, map[string]string{"minify": "true"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	match := decodeResponse[map[string]string](t, response, http.StatusOK)
+	if match["checksum"] != "1" {
+		t.Fatalf("unexpected result %v", match)
+	}
+}
+
+func TestAnalyzeJobQueue(t *testing.T) {
+	release := make(chan struct{})
+	httpServer := newTestServer(t, Options{Concurrency: 1, QueueSize: 1}, func(ctx context.Context, job *job) error {
+		select {
+		case <-release:
+		case <-ctx.Done():
+			return &api.CancelledError{Err: ctx.Err()}
+		}
+
+		return os.WriteFile(filepath.Join(job.outputPath, "myDemo_kills.csv"), []byte("tick\n"), 0644)
+	})
+
+	var jobs []jobResponse
+	for len(jobs) < 2 {
+		response, err := http.DefaultClient.Do(newUploadRequest(t, httpServer.URL, map[string]string{"format": "csv", "async": "true"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		jobs = append(jobs, decodeResponse[jobResponse](t, response, http.StatusAccepted))
+	}
+
+	// The first job may not have been picked by the worker yet.
+	for {
+		response, err := http.Get(httpServer.URL + "/jobs/" + jobs[0].ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decodeResponse[jobResponse](t, response, http.StatusOK).Status == jobStatusRunning {
+			break
+		}
+	}
+
+	response, err := http.DefaultClient.Do(newUploadRequest(t, httpServer.URL, map[string]string{"async": "true"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errResponse := decodeResponse[errorResponse](t, response, http.StatusServiceUnavailable); errResponse.Code != "QueueFull" {
+		t.Fatalf("expected QueueFull error, got %v", errResponse)
+	}
+
+	response, err = http.Get(httpServer.URL + "/jobs/" + jobs[1].ID + "/result")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decodeResponse[errorResponse](t, response, http.StatusConflict)
+
+	close(release)
+	for _, job := range jobs {
+		for {
+			response, err := http.Get(httpServer.URL + "/jobs/" + job.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decodeResponse[jobResponse](t, response, http.StatusOK).Status == jobStatusSucceeded {
+				break
+			}
+		}
+
+		response, err := http.Get(httpServer.URL + "/jobs/" + job.ID + "/result")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if response.Header.Get("Content-Type") != "text/csv" {
+			t.Fatalf("unexpected content type %q", response.Header.Get("Content-Type"))
+		}
+		response.Body.Close()
+	}
+}
+
+func TestAnalyzePath(t *testing.T) {
+	demosFolderPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(demosFolderPath, "myDemo.dem"), []byte("demo content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	httpServer := newTestServer(t, Options{DemosFolderPath: demosFolderPath}, func(ctx context.Context, job *job) error {
+		return api.ErrUnknownSource
+	})
+
+	tests := []struct {
+		path           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"myDemo.dem", http.StatusUnprocessableEntity, "UnknownSource"},
+		{"../myDemo.dem", http.StatusForbidden, "PathNotAllowed"},
+		{"missing.dem", http.StatusNotFound, "DemoNotFound"},
+	}
+
+	for _, test := range tests {
+		body, _ := json.Marshal(map[string]string{"path": test.path})
+		response, err := http.Post(httpServer.URL+"/analyze", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		errResponse := decodeResponse[errorResponse](t, response, test.expectedStatus)
+		if errResponse.Code != test.expectedCode {
+			t.Errorf("%s: expected code %s, got %s", test.path, test.expectedCode, errResponse.Code)
+		}
+	}
+}
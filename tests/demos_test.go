@@ -1,7 +1,13 @@
 package tests
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/akiver/cs-demo-analyzer/pkg/api"
 	"github.com/akiver/cs-demo-analyzer/pkg/api/constants"
@@ -135,3 +141,121 @@ func TestDemos(t *testing.T) {
 	runDemoTestCases(t, "cs2", cs2DemoTestCases)
 	runDemoTestCases(t, "csgo", csgoDemoTestCases)
 }
+
+// TestExcludeEventsKeepsStats checks that excluding the events used to compute stats doesn't change the analysis.
+// The eBot demo is used because its knife round is detected from the kills.
+func TestExcludeEventsKeepsStats(t *testing.T) {
+	demoPath := testsutils.GetDemoPath("cs2", "ebot_monte_vs_og_roobet_cup_2023_anubis")
+	analyze := func(exclude []constants.EventCategory) *api.Match {
+		match, err := api.AnalyzeDemo(demoPath, api.AnalyzeDemoOptions{
+			Source:        constants.DemoSourceEbot,
+			ExcludeEvents: exclude,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return match
+	}
+
+	match := analyze(nil)
+	matchWithoutKills := analyze([]constants.EventCategory{
+		constants.EventCategoryKills,
+		constants.EventCategoryDamages,
+		constants.EventCategoryShots,
+	})
+
+	if len(matchWithoutKills.Rounds) != len(match.Rounds) {
+		t.Fatalf("expected %d rounds, got %d", len(match.Rounds), len(matchWithoutKills.Rounds))
+	}
+	for steamID, player := range match.PlayersBySteamID {
+		expectedJSON, err := json.Marshal(player)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actualJSON, err := json.Marshal(matchWithoutKills.PlayersBySteamID[steamID])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(actualJSON) != string(expectedJSON) {
+			t.Errorf("stats of player %s changed when kills are excluded\nexpected: %s\ngot:      %s", player.Name, expectedJSON, actualJSON)
+		}
+	}
+}
+
+// TestDiscardEventsKeepsShotCount checks that the shot count of the match, exported with NDJSON and streamed by the gRPC
+// server, doesn't change when events are discarded.
+func TestDiscardEventsKeepsShotCount(t *testing.T) {
+	demoPath := testsutils.GetDemoPath("cs2", "ebot_monte_vs_og_roobet_cup_2023_anubis")
+	analyze := func(discardEvents bool) *api.Match {
+		match, err := api.AnalyzeDemo(demoPath, api.AnalyzeDemoOptions{
+			Source:        constants.DemoSourceEbot,
+			EventSink:     api.NopEventSink{},
+			DiscardEvents: discardEvents,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return match
+	}
+
+	expectedShotCount := analyze(false).ShotCount()
+	if shotCount := analyze(true).ShotCount(); shotCount != expectedShotCount {
+		t.Errorf("expected %d shots when events are discarded, got %d", expectedShotCount, shotCount)
+	}
+}
+
+// diagnosticCounter counts the diagnostics it receives.
+type diagnosticCounter struct {
+	count int
+}
+
+func (counter *diagnosticCounter) LogDiagnostic(diagnostic api.Diagnostic) {
+	counter.count++
+}
+
+// TestAnalysisCache checks that a demo exported twice with the cache is parsed once and exported the same way.
+func TestAnalysisCache(t *testing.T) {
+	demoPath := testsutils.GetDemoPath("cs2", "ebot_monte_vs_og_roobet_cup_2023_anubis")
+	cacheFolderPath := t.TempDir()
+	export := func() (output []byte, progressCount int, diagnosticCount int) {
+		logger := &diagnosticCounter{}
+		outputFolderPath := t.TempDir()
+		err := api.AnalyzeAndExportDemoContext(context.Background(), demoPath, outputFolderPath, api.AnalyzeAndExportDemoOptions{
+			Source: constants.DemoSourceEbot,
+			Format: constants.ExportFormatJSON,
+			Progress: func(progress api.AnalyzeProgress) {
+				progressCount++
+			},
+			// Report the progress as often as possible to detect the parsing.
+			ProgressInterval: time.Nanosecond,
+			Logger:           logger,
+			CacheFolderPath:  cacheFolderPath,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		output, err = os.ReadFile(filepath.Join(outputFolderPath, "ebot_monte_vs_og_roobet_cup_2023_anubis.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return output, progressCount, logger.count
+	}
+
+	output, _, diagnosticCount := export()
+	cachedOutput, cachedProgressCount, cachedDiagnosticCount := export()
+
+	// The progress is reported once when the match is read from the cache.
+	if cachedProgressCount != 1 {
+		t.Errorf("expected the demo to not be parsed again, the progress has been reported %d times", cachedProgressCount)
+	}
+	if cachedDiagnosticCount != diagnosticCount {
+		t.Errorf("expected %d diagnostics to be logged from the cache, got %d", diagnosticCount, cachedDiagnosticCount)
+	}
+	if !bytes.Equal(cachedOutput, output) {
+		t.Error("the export of the cached match differs from the export of the analyzed match")
+	}
+}